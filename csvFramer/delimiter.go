@@ -0,0 +1,147 @@
+package csvFramer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// DelimiterAuto detects the delimiter among comma, semicolon, tab and pipe from the first lines of the csv
+const DelimiterAuto = "auto"
+
+// ErrInvalidDelimiter is returned when the delimiter contains quotes or line breaks
+var ErrInvalidDelimiter = errors.New("invalid delimiter")
+
+var delimiterCandidates = []rune{',', ';', '\t', '|'}
+
+const (
+	sniffSize  = 64 * 1024 // bytes read ahead to detect the delimiter
+	sniffLines = 10        // lines used to detect the delimiter
+)
+
+// multiCharDelimiterComma replaces the multi character delimiters, outside the quoted fields, before the csv is parsed.
+// The ascii unit separator is not expected in the csv text
+const multiCharDelimiterComma = '\x1f'
+
+// csvDelimiter returns the reader and the delimiter rune of the csv reader. Escaped tabs such as `\t` are unescaped.
+// The delimiter is detected with DelimiterAuto, and the multi character delimiters such as `||` are replaced by a single rune
+func csvDelimiter(reader io.Reader, options CSVFramerOptions) (io.Reader, rune, error) {
+	delimiter := strings.ReplaceAll(options.Delimiter, `\t`, "\t")
+	switch {
+	case delimiter == "":
+		return reader, ',', nil
+	case strings.EqualFold(delimiter, DelimiterAuto):
+		br := bufio.NewReaderSize(reader, sniffSize)
+		peek, err := br.Peek(sniffSize)
+		return br, sniffDelimiter(string(peek), err == nil, options.Comment), nil
+	case strings.ContainsAny(delimiter, "\"\r\n") || !utf8.ValidString(delimiter):
+		return reader, 0, fmt.Errorf("%w %q", ErrInvalidDelimiter, options.Delimiter)
+	case utf8.RuneCountInString(delimiter) == 1:
+		r, _ := utf8.DecodeRuneInString(delimiter)
+		return reader, r, nil
+	}
+	return &delimiterReplacer{r: bufio.NewReader(reader), delimiter: delimiter, fieldStart: true}, multiCharDelimiterComma, nil
+}
+
+// sniffDelimiter returns the candidate found the same number of times in most of the first lines, so that the title lines of the reports don't matter.
+// More occurrences per line win the ties, then the order of the candidates. The last line is ignored when the text is truncated. Comma is returned when no candidate is found
+func sniffDelimiter(text string, truncated bool, comment string) rune {
+	lines := strings.Split(text, "\n")
+	if truncated && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+	sample := []string{}
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || (comment != "" && strings.HasPrefix(line, comment[:1])) {
+			continue
+		}
+		if sample = append(sample, line); len(sample) == sniffLines {
+			break
+		}
+	}
+	best, bestLines, bestCount := ',', 0, 0
+	for _, candidate := range delimiterCandidates {
+		counts := map[int]int{} // number of lines for each count of the candidate
+		for _, line := range sample {
+			if count := countUnquoted(line, candidate); count > 0 {
+				counts[count]++
+			}
+		}
+		for count, consistent := range counts {
+			if consistent > bestLines || consistent == bestLines && count > bestCount {
+				best, bestLines, bestCount = candidate, consistent, count
+			}
+		}
+	}
+	return best
+}
+
+// countUnquoted counts the occurrences of the rune outside the double quotes
+func countUnquoted(line string, r rune) int {
+	count, quoted := 0, false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == r && !quoted:
+			count++
+		}
+	}
+	return count
+}
+
+// delimiterReplacer replaces the multi character delimiter by multiCharDelimiterComma, except within the quoted fields.
+// Like the csv reader, a field is quoted only when it starts with a quote
+type delimiterReplacer struct {
+	r          *bufio.Reader
+	delimiter  string
+	quoted     bool
+	fieldStart bool
+	buf        []byte
+	pending    []byte
+	err        error
+}
+
+func (d *delimiterReplacer) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		var line string
+		line, d.err = d.r.ReadString('\n')
+		d.pending = d.replace(line)
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+func (d *delimiterReplacer) replace(line string) []byte {
+	d.buf = d.buf[:0]
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case d.quoted && c == '"' && i+1 < len(line) && line[i+1] == '"':
+			d.buf = append(d.buf, c, c)
+			i += 2
+			continue
+		case d.quoted && c == '"':
+			d.quoted = false
+		case !d.quoted && d.fieldStart && c == '"':
+			d.quoted = true
+		case !d.quoted && strings.HasPrefix(line[i:], d.delimiter):
+			d.buf = append(d.buf, string(multiCharDelimiterComma)...)
+			i += len(d.delimiter)
+			d.fieldStart = true
+			continue
+		}
+		d.buf = append(d.buf, c)
+		d.fieldStart = !d.quoted && c == '\n'
+		i++
+	}
+	return d.buf
+}
@@ -0,0 +1,51 @@
+package csvFramer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/gframer"
+)
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want rune
+	}{
+		{name: "comma", text: "a,b,c\n1,2,3\n4,5,6", want: ','},
+		{name: "semicolon with decimal commas", text: "a;b\n1,5;2\n3;4,25", want: ';'},
+		{name: "tab", text: "a\tb\tc\n1\t2\t3", want: '\t'},
+		{name: "pipe with quoted commas", text: "a|b\n\"x,y,z\"|1\n\"p,q\"|2", want: '|'},
+		{name: "single column", text: "a\n1\n2", want: ','},
+		{name: "truncated last line", text: "a;b\n1;2\n3,4,5", want: ';'},
+		{name: "title line", text: "Sales report\na;b\n1;2\n3;4", want: ';'},
+		{name: "title line with comma", text: "Sales report, March 2022\na;b;c\n1,5;2;3\n4;5;6", want: ';'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, string(tt.want), string(sniffDelimiter(tt.text, tt.name == "truncated last line", "")))
+		})
+	}
+}
+
+func TestCsvStringToFrameDelimiter(t *testing.T) {
+	for _, delimiter := range []string{`"`, "\n", "|\r"} {
+		_, err := CsvStringToFrame("a,b\n1,2", CSVFramerOptions{Delimiter: delimiter})
+		require.ErrorIs(t, err, ErrInvalidDelimiter)
+	}
+	csvString := strings.Join([]string{`a; b; c`, `1; "2; 3"; 4`}, "\n")
+	frame, err := CsvStringToFrame(csvString, CSVFramerOptions{Delimiter: "; ", FieldOrder: gframer.FieldOrderSource})
+	require.Nil(t, err)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "2; 3", *frame.Fields[1].At(0).(*string))
+	frame, err = CsvStringToFrame("Sales report\na;b\n1;2\n3;4", CSVFramerOptions{Delimiter: DelimiterAuto, HeaderRow: 1, FieldOrder: gframer.FieldOrderSource})
+	require.Nil(t, err)
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, "a", frame.Fields[0].Name)
+	require.Equal(t, 2, frame.Rows())
+	frame, err = CsvStringToFrame("a¦b\n1¦2", CSVFramerOptions{Delimiter: "¦"})
+	require.Nil(t, err)
+	require.Len(t, frame.Fields, 2)
+}
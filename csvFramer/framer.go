@@ -1,89 +1,37 @@
 package csvFramer
 
 import (
-	"encoding/csv"
-	"errors"
-	"fmt"
-	"io"
+	"context"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
 	"github.com/yesoreyeram/grafana-framer/gframer"
 )
 
 type CSVFramerOptions struct {
 	FrameName          string
 	Columns            []gframer.ColumnSelector
-	Delimiter          string
+	Delimiter          string // `,` by default. `\t` for tab, `auto` to detect comma, semicolon, tab or pipe. Multi character delimiters such as `||` are supported
 	SkipLinesWithError bool
 	Comment            string
 	RelaxColumnCount   bool
 	NoHeaders          bool
+	FieldOrder         gframer.FieldOrder
+	Strict             bool                  // fail when a value can't be converted to the column type instead of setting null
+	TimeZone           string                // default IANA time zone name of the timestamps without zone information
+	DateOrder          framerUtils.DateOrder // `MDY` | `DMY` | `YMD`. default order of the ambiguous dates such as 01/02/2006
+	InferTypes         bool                  // detect the number, boolean and timestamp columns instead of returning every column as string. Types given by Columns take precedence
+	SkipLines          int                   // number of lines to skip before parsing, such as the title lines of the exported reports
+	HeaderRow          int                   // index of the header record, starting from 0. The records before the header are skipped. Without headers, the index of the first data record
+	SkipFooterLines    int                   // number of records to skip at the end, such as the total rows
+	MaxRows            int                   // maximum number of data rows. Reading stops after that. No limit when 0
+	SkipRowsMatching   string                // regular expression. The data rows with a value matching it, such as ^Total$, are skipped
 }
 
 func CsvStringToFrame(csvString string, options CSVFramerOptions) (frame *data.Frame, err error) {
 	if strings.TrimSpace(csvString) == "" {
-		return frame, errors.New("empty/invalid csv")
+		return frame, gframer.ErrEmptyInput
 	}
-	r := csv.NewReader(strings.NewReader(csvString))
-	r.LazyQuotes = true
-	if options.Comment != "" {
-		r.Comment = rune(options.Comment[0])
-	}
-	if options.Delimiter != "" {
-		r.Comma = rune(options.Delimiter[0])
-	}
-	if options.RelaxColumnCount {
-		r.FieldsPerRecord = -1
-	}
-	parsedCSV := [][]string{}
-	for {
-		record, err := r.Read()
-		if err == io.EOF {
-			break
-		}
-		if err == nil {
-			parsedCSV = append(parsedCSV, record)
-			continue
-		}
-		if !options.SkipLinesWithError {
-			return frame, fmt.Errorf("error reading csv response. %w, %v", err, record)
-		}
-	}
-	out := []interface{}{}
-	header := []string{}
-	records := [][]string{}
-	if !options.NoHeaders {
-		header = parsedCSV[0]
-		for idx, hItem := range header {
-			for _, col := range options.Columns {
-				if col.Selector == hItem && col.Alias != "" {
-					header[idx] = col.Alias
-				}
-			}
-		}
-		records = parsedCSV[1:]
-	}
-	if options.NoHeaders {
-		records = parsedCSV
-		if len(records) > 0 {
-			for i := 0; i < len(records[0]); i++ {
-				header = append(header, fmt.Sprintf("%d", i+1))
-			}
-		}
-	}
-	for _, row := range records {
-		item := map[string]interface{}{}
-		for colId, col := range header {
-			if colId < len(row) {
-				item[col] = row[colId]
-			}
-		}
-		out = append(out, item)
-	}
-	framerOptions := gframer.FramerOptions{
-		FrameName: options.FrameName,
-		Columns:   options.Columns,
-	}
-	return gframer.ToDataFrame(out, framerOptions)
+	return CsvReaderToFrame(context.Background(), strings.NewReader(csvString), options)
 }
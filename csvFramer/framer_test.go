@@ -1,13 +1,16 @@
 package csvFramer
 
 import (
+	"encoding/csv"
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
 	"github.com/yesoreyeram/grafana-framer/gframer"
 )
 
@@ -20,7 +23,13 @@ func TestCsvStringToFrame(t *testing.T) {
 	}{
 		{
 			name:      "empty csv should return error",
-			wantError: errors.New("empty/invalid csv"),
+			wantError: gframer.ErrEmptyInput,
+		},
+		{
+			name:      "csv with only comments should return error",
+			csvString: strings.Join([]string{`# foo`, `# bar`}, "\n"),
+			options:   CSVFramerOptions{Comment: "#"},
+			wantError: gframer.ErrEmptyInput,
 		},
 		{
 			name:      "valid csv should not return error",
@@ -72,13 +81,100 @@ func TestCsvStringToFrame(t *testing.T) {
 			csvString: strings.Join([]string{`# foo`, `a,b,c`, `#01,02,03`, `1,2,3`, `11,12,13`, `21,22,23`, `#`}, "\n"),
 			options:   CSVFramerOptions{Comment: "#"},
 		},
+		{
+			name:      "field order source",
+			csvString: strings.Join([]string{`time,host,value`, `1,foo,3`, `11,bar,13`}, "\n"),
+			options:   CSVFramerOptions{FieldOrder: gframer.FieldOrderSource},
+		},
+		{
+			name:      "boolean columns",
+			csvString: strings.Join([]string{`enabled,active,status,flag`, `yes,Y,up,1`, `no,n,down,0`, `TRUE,on,up,2`, `False,Off,unknown,`}, "\n"),
+			options: CSVFramerOptions{FieldOrder: gframer.FieldOrderSource, Columns: []gframer.ColumnSelector{
+				{Selector: "enabled", Type: "boolean"},
+				{Selector: "active", Type: "boolean"},
+				{Selector: "status", Type: "boolean", TrueValues: []string{"up"}, FalseValues: []string{"down"}},
+				{Selector: "flag", Type: "boolean"},
+			}},
+		},
+		{
+			name:      "epoch auto detection",
+			csvString: strings.Join([]string{`time,value`, `1672531200000000000,1`, `1672531260000000000,2`, `,3`}, "\n"),
+			options:   CSVFramerOptions{Columns: []gframer.ColumnSelector{{Selector: "time", Type: "timestamp_epoch_auto"}}},
+		},
+		{
+			name:      "day first dates",
+			csvString: strings.Join([]string{`date;week;value`, `03/01/2022;2022-W01-1;1`, `04.01.2022 2:30 PM;2022-W01-2;2`, `05-Jan-2022;2022-W01-3;3`}, "\n"),
+			options: CSVFramerOptions{Delimiter: ";", DateOrder: framerUtils.DateOrderDMY, FieldOrder: gframer.FieldOrderSource, Columns: []gframer.ColumnSelector{
+				{Selector: "date", Type: "timestamp"},
+				{Selector: "week", Type: "timestamp"},
+				{Selector: "value", Type: "number"},
+			}},
+		},
+		{
+			name:      "spreadsheet numbers",
+			csvString: strings.Join([]string{`item;price;change;total`, `foo;"1.234,56 €";"12,5%";"1.000"`, `bar;"(300,00 €)";"-3%";"(2.000,5)"`, `baz;" 0,99";;"n/a"`}, "\n"),
+			options: CSVFramerOptions{Delimiter: ";", FieldOrder: gframer.FieldOrderSource, Columns: []gframer.ColumnSelector{
+				{Selector: "price", Type: "number", DecimalSeparator: ",", ThousandsSeparator: ".", StripCurrency: true, AccountingNegative: true},
+				{Selector: "change", Type: "number", DecimalSeparator: ",", StripPercent: true},
+				{Selector: "total", Type: "number", DecimalSeparator: ",", ThousandsSeparator: ".", AccountingNegative: true},
+			}},
+		},
+		{
+			name:      "auto delimiter",
+			csvString: strings.Join([]string{`# a,b,c`, `name;value;note`, `foo;1,5;"a;b"`, `bar;2;c,d`}, "\n"),
+			options:   CSVFramerOptions{Delimiter: DelimiterAuto, Comment: "#", FieldOrder: gframer.FieldOrderSource},
+		},
+		{
+			name:      "escaped tab delimiter",
+			csvString: strings.Join([]string{"a\tb", "1\t2", "3\t4"}, "\n"),
+			options:   CSVFramerOptions{Delimiter: `\t`},
+		},
+		{
+			name:      "multi character delimiter",
+			csvString: strings.Join([]string{`a||b||c`, `1||"x||y"||3`, `4|5||"say ""hi"""||6`}, "\n"),
+			options:   CSVFramerOptions{Delimiter: "||"},
+		},
+		{
+			name: "exported report",
+			csvString: strings.Join([]string{
+				`"Sales report, March 2022"`,
+				`Generated on 2022-04-01`,
+				`region,,`,
+				`region,product,amount`,
+				`north,foo,10`,
+				`north,subtotal,10`,
+				`south,bar,20`,
+				`,,`,
+				`Total: 30`,
+			}, "\n"),
+			options: CSVFramerOptions{SkipLines: 2, HeaderRow: 1, SkipFooterLines: 2, SkipRowsMatching: "^subtotal$", FieldOrder: gframer.FieldOrderSource},
+		},
+		{
+			name:      "max rows",
+			csvString: strings.Join([]string{`a,b`, `1,2`, `3,4`, `5,6`}, "\n"),
+			options:   CSVFramerOptions{MaxRows: 2},
+		},
+		{
+			name:      "infer types",
+			csvString: strings.Join([]string{`time,host,value,up,count,note`, `2022-03-01 10:30,foo,1.5,true,1,`, `2022-03-01 10:31,bar,,FALSE,0,`, `2022-03-01 10:32,baz,-3,false,2,`}, "\n"),
+			options:   CSVFramerOptions{FieldOrder: gframer.FieldOrderSource, InferTypes: true},
+		},
+		{
+			name:      "infer types with columns",
+			csvString: strings.Join([]string{`time,code,value`, `03/01/2022,001,1`, `13/01/2022,002,2`}, "\n"),
+			options: CSVFramerOptions{FieldOrder: gframer.FieldOrderSource, InferTypes: true, Columns: []gframer.ColumnSelector{
+				{Selector: "time"},
+				{Selector: "code", Type: "string"},
+				{Selector: "value"},
+			}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gotFrame, err := CsvStringToFrame(tt.csvString, tt.options)
 			if tt.wantError != nil {
 				require.NotNil(t, err)
-				assert.Equal(t, tt.wantError, err)
+				assert.ErrorIs(t, err, tt.wantError)
 				return
 			}
 			require.Nil(t, err)
@@ -90,3 +186,60 @@ func TestCsvStringToFrame(t *testing.T) {
 		})
 	}
 }
+
+func TestCsvStringToFrameErrors(t *testing.T) {
+	csvString := strings.Join([]string{`a,b,c`, `1,2,3`, `11,12`, `21,22,23`}, "\n")
+	_, err := CsvStringToFrame(csvString, CSVFramerOptions{})
+	var invalidInput *gframer.ErrInvalidInput
+	require.True(t, errors.As(err, &invalidInput))
+	require.Equal(t, "csv", invalidInput.Format)
+	require.Equal(t, 3, invalidInput.Line)
+	require.ErrorIs(t, err, csv.ErrFieldCount)
+}
+
+func TestCsvStringToFrameSkipRows(t *testing.T) {
+	_, err := CsvStringToFrame("a,b\n1,2", CSVFramerOptions{SkipRowsMatching: "("})
+	require.NotNil(t, err)
+	_, err = CsvStringToFrame("title\na,b\n1,2", CSVFramerOptions{SkipLines: 3})
+	require.ErrorIs(t, err, gframer.ErrEmptyInput)
+	frame, err := CsvStringToFrame("a,b\n1,2\n3,4\n5,6", CSVFramerOptions{HeaderRow: 2, NoHeaders: true, MaxRows: 1})
+	require.Nil(t, err)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, "3", *frame.Fields[0].At(0).(*string))
+	t.Run("footer with different number of fields", func(t *testing.T) {
+		csvString := strings.Join([]string{`a,b`, `1,2`, `3,4,5`, `Total: 3`}, "\n")
+		_, err := CsvStringToFrame(csvString, CSVFramerOptions{SkipFooterLines: 1})
+		var invalidInput *gframer.ErrInvalidInput
+		require.True(t, errors.As(err, &invalidInput))
+		require.Equal(t, 3, invalidInput.Line)
+		frame, err := CsvStringToFrame(csvString, CSVFramerOptions{SkipFooterLines: 2})
+		require.Nil(t, err)
+		require.Equal(t, 1, frame.Rows())
+	})
+}
+
+func TestCsvStringToFrameDateOrder(t *testing.T) {
+	csvString := strings.Join([]string{`date,value`, `12/25/2022,1`, `01/02/2022,2`, `13/02/2022,3`, `03/04/2022,4`}, "\n")
+	options := CSVFramerOptions{DateOrder: framerUtils.DateOrderDMY, Columns: []gframer.ColumnSelector{{Selector: "date", Type: "timestamp"}}}
+	frame, err := CsvStringToFrame(csvString, options)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+	require.Equal(t, time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+	require.Equal(t, time.Date(2022, 2, 13, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(2).(*time.Time))
+	require.Equal(t, time.Date(2022, 4, 3, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(3).(*time.Time))
+}
+
+func TestCsvStringToFrameStrict(t *testing.T) {
+	csvString := strings.Join([]string{`a,b`, `1,2`, `foo,12`}, "\n")
+	options := CSVFramerOptions{Columns: []gframer.ColumnSelector{{Selector: "a", Type: "number"}}}
+	gotFrame, err := CsvStringToFrame(csvString, options)
+	require.Nil(t, err)
+	require.Len(t, gotFrame.Meta.Notices, 1)
+	options.Strict = true
+	_, err = CsvStringToFrame(csvString, options)
+	var conversionError *gframer.ErrConversion
+	require.True(t, errors.As(err, &conversionError))
+	require.Equal(t, "a", conversionError.Column)
+	require.Equal(t, 1, conversionError.Row)
+	require.Equal(t, "foo", conversionError.Value)
+}
@@ -0,0 +1,195 @@
+package csvFramer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-framer/gframer"
+)
+
+// contextCheckInterval is the number of records read between the checks of the context cancellation
+const contextCheckInterval = 1024
+
+// CsvReaderToFrame reads the csv records one at a time and appends them straight to the typed fields of the frame,
+// without keeping the parsed records. The columns without type, and the columns of the types which depend on all the values
+// of the column such as timestamp_epoch_auto, are kept as string fields until the frame is built.
+// Reading stops with the context error when the context is cancelled
+func CsvReaderToFrame(ctx context.Context, reader io.Reader, options CSVFramerOptions) (frame *data.Frame, err error) {
+	var skipRows *regexp.Regexp
+	if options.SkipRowsMatching != "" {
+		if skipRows, err = regexp.Compile(options.SkipRowsMatching); err != nil {
+			return frame, fmt.Errorf("invalid skip rows pattern %q. %w", options.SkipRowsMatching, err)
+		}
+	}
+	if reader, err = skipLines(reader, options.SkipLines); err != nil {
+		return frame, csvReadError(err)
+	}
+	r, err := newCSVReader(reader, options)
+	if err != nil {
+		return frame, err
+	}
+	var builder *gframer.FrameBuilder
+	var row []interface{}
+	var footer []pendingRecord
+	fieldCount, records, rows := 0, 0, 0
+	for count := 0; options.MaxRows <= 0 || rows < options.MaxRows; count++ {
+		if count%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return frame, err
+			}
+		}
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err == nil && builder != nil && !options.RelaxColumnCount && len(record) != fieldCount {
+			line, _ := r.FieldPos(0)
+			err = &csv.ParseError{StartLine: line, Line: line, Column: 1, Err: csv.ErrFieldCount}
+		}
+		if err != nil && builder == nil {
+			if options.SkipLinesWithError {
+				continue
+			}
+			return frame, csvReadError(err)
+		}
+		if builder == nil {
+			if records++; records <= options.HeaderRow {
+				continue
+			}
+			fieldCount = len(record)
+			header := csvHeader(record, options)
+			if builder, err = gframer.NewFrameBuilder(options.FrameName, header, framerOptions(options)); err != nil {
+				return frame, err
+			}
+			row = make([]interface{}, len(header))
+			if !options.NoHeaders {
+				continue
+			}
+		}
+		if options.SkipFooterLines > 0 {
+			// the records are held back until more than SkipFooterLines records follow them
+			footer = append(footer, pendingRecord{record: append([]string(nil), record...), err: err})
+			if len(footer) <= options.SkipFooterLines {
+				continue
+			}
+			record, err = footer[0].record, footer[0].err
+			footer = footer[1:]
+		}
+		if err != nil {
+			if options.SkipLinesWithError {
+				continue
+			}
+			return frame, csvReadError(err)
+		}
+		if skipRows != nil && matchesAnyValue(skipRows, record) {
+			continue
+		}
+		row = row[:0]
+		for _, value := range record {
+			row = append(row, value)
+		}
+		if err := builder.AppendRow(row); err != nil {
+			return frame, err
+		}
+		rows++
+	}
+	if builder == nil {
+		return frame, gframer.ErrEmptyInput
+	}
+	return builder.Frame()
+}
+
+// pendingRecord is a record, or the error reading it, held back until it is known not to be one of the footer records
+type pendingRecord struct {
+	record []string
+	err    error
+}
+
+// skipLines skips the first lines of the text, such as the title of the reports, before the csv is parsed
+func skipLines(reader io.Reader, count int) (io.Reader, error) {
+	if count <= 0 {
+		return reader, nil
+	}
+	br := bufio.NewReader(reader)
+	for i := 0; i < count; i++ {
+		if _, err := br.ReadString('\n'); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return br, err
+		}
+	}
+	return br, nil
+}
+
+func matchesAnyValue(pattern *regexp.Regexp, record []string) bool {
+	for _, value := range record {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func newCSVReader(reader io.Reader, options CSVFramerOptions) (*csv.Reader, error) {
+	reader, comma, err := csvDelimiter(reader, options)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(reader)
+	r.Comma = comma
+	r.LazyQuotes = true
+	r.ReuseRecord = true
+	if options.Comment != "" {
+		r.Comment = rune(options.Comment[0])
+	}
+	// the number of fields is checked against the header record, as the skipped records before the header and the footer records may differ
+	r.FieldsPerRecord = -1
+	return r, nil
+}
+
+// csvHeader returns the field names of the first record. The columns with alias are renamed to the alias.
+// Without headers, the fields are named by their position starting from 1
+func csvHeader(record []string, options CSVFramerOptions) []string {
+	header := make([]string, len(record))
+	for idx, hItem := range record {
+		header[idx] = hItem
+		if options.NoHeaders {
+			header[idx] = fmt.Sprintf("%d", idx+1)
+			continue
+		}
+		for _, col := range options.Columns {
+			if col.Selector == hItem && col.Alias != "" {
+				header[idx] = col.Alias
+			}
+		}
+	}
+	return header
+}
+
+func csvReadError(err error) error {
+	out := &gframer.ErrInvalidInput{Format: "csv", Err: err}
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		out.Line = parseError.Line
+	}
+	return out
+}
+
+func framerOptions(options CSVFramerOptions) gframer.FramerOptions {
+	return gframer.FramerOptions{
+		FrameName:  options.FrameName,
+		Columns:    options.Columns,
+		FieldOrder: options.FieldOrder,
+		Strict:     options.Strict,
+		TimeZone:   options.TimeZone,
+		DateOrder:  options.DateOrder,
+		InferTypes: options.InferTypes,
+	}
+}
@@ -0,0 +1,118 @@
+package csvFramer
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/gframer"
+)
+
+func TestCsvReaderToFrame(t *testing.T) {
+	csvString := benchmarkCSV(3000)
+	options := CSVFramerOptions{FrameName: "foo", Columns: []gframer.ColumnSelector{{Selector: "time", Type: "timestamp"}, {Selector: "host", Type: "string"}, {Selector: "value", Type: "number"}}}
+	gotFrame, err := CsvReaderToFrame(context.Background(), strings.NewReader(csvString), options)
+	require.Nil(t, err)
+	wantFrame, err := gframer.ToDataFrame(csvRows(csvString), gframer.FramerOptions{FrameName: options.FrameName, Columns: options.Columns})
+	require.Nil(t, err)
+	require.Equal(t, wantFrame, gotFrame)
+	require.Equal(t, 3000, gotFrame.Rows())
+	t.Run("without columns", func(t *testing.T) {
+		csvString := csvString + "2022-03-01T11:00:00Z,,n/a,\n"
+		for _, inferTypes := range []bool{false, true} {
+			gotFrame, err := CsvReaderToFrame(context.Background(), strings.NewReader(csvString), CSVFramerOptions{InferTypes: inferTypes})
+			require.Nil(t, err)
+			wantFrame, err := gframer.ToDataFrame(csvRows(csvString), gframer.FramerOptions{InferTypes: inferTypes})
+			require.Nil(t, err)
+			require.Equal(t, wantFrame, gotFrame)
+		}
+	})
+	t.Run("context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := CsvReaderToFrame(ctx, strings.NewReader(csvString), options)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("empty reader", func(t *testing.T) {
+		_, err := CsvReaderToFrame(context.Background(), strings.NewReader(""), options)
+		require.ErrorIs(t, err, gframer.ErrEmptyInput)
+	})
+}
+
+// csvRows returns the records of the csv as objects with the header names as keys, the input of ToDataFrame
+func csvRows(csvString string) []interface{} {
+	records, _ := csv.NewReader(strings.NewReader(csvString)).ReadAll()
+	rows := []interface{}{}
+	for _, record := range records[1:] {
+		row := gframer.NewOrderedMap()
+		for idx, name := range records[0] {
+			row.Set(name, record[idx])
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func benchmarkCSV(rows int) string {
+	var sb strings.Builder
+	sb.WriteString("time,host,value,status\n")
+	for i := 0; i < rows; i++ {
+		sb.WriteString(fmt.Sprintf("2022-03-01T10:%02d:%02dZ,host-%d,%d.5,ok\n", (i/60)%60, i%60, i%10, i))
+	}
+	return sb.String()
+}
+
+var benchmarkOptions = CSVFramerOptions{Columns: []gframer.ColumnSelector{
+	{Selector: "time", Type: "timestamp"},
+	{Selector: "host", Type: "string"},
+	{Selector: "value", Type: "number"},
+	{Selector: "status", Type: "string"},
+}}
+
+func BenchmarkCsvReaderToFrame(b *testing.B) {
+	csvString := benchmarkCSV(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CsvReaderToFrame(context.Background(), strings.NewReader(csvString), benchmarkOptions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCsvRowsToDataFrame frames the csv the way it was done before the reader: all the records are read, converted to objects and given to ToDataFrame
+func BenchmarkCsvRowsToDataFrame(b *testing.B) {
+	csvString := benchmarkCSV(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gframer.ToDataFrame(csvRows(csvString), gframer.FramerOptions{Columns: benchmarkOptions.Columns}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCsvReaderToFrameWithoutColumns(b *testing.B) {
+	csvString := benchmarkCSV(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CsvReaderToFrame(context.Background(), strings.NewReader(csvString), CSVFramerOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCsvRowsToDataFrameWithoutColumns(b *testing.B) {
+	csvString := benchmarkCSV(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gframer.ToDataFrame(csvRows(csvString), gframer.FramerOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package fixedWidthFramer
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// ErrInvalidBoundaries is returned when the boundaries are negative or not in increasing order
+var ErrInvalidBoundaries = errors.New("invalid boundaries")
+
+func validateBoundaries(boundaries []int) error {
+	for idx, b := range boundaries {
+		if b < 0 || (idx > 0 && b <= boundaries[idx-1]) {
+			return fmt.Errorf("%w %v. boundaries must not be negative and must be in increasing order", ErrInvalidBoundaries, boundaries)
+		}
+	}
+	return nil
+}
+
+// inferBoundaries returns the start positions of the header names. The first column starts at 0.
+// Names separated by a single space, such as `Mounted on`, are kept together when no value starts below the following name.
+// The start of a column is moved left, up to the end of the previous name, when the right aligned values are wider than the name
+func inferBoundaries(header []rune, lines [][]rune) []int {
+	starts, ends := headerNames(header)
+	if len(starts) == 0 {
+		return []int{0}
+	}
+	boundaries := []int{0}
+	for idx := 1; idx < len(starts); idx++ {
+		start, previousEnd := starts[idx], ends[idx-1]
+		next := -1
+		if idx+1 < len(starts) {
+			next = starts[idx+1]
+		}
+		if start == previousEnd+1 && !hasValueStarts(lines, start, next) {
+			continue
+		}
+		b := start
+		for b > previousEnd && crossesBoundary(lines, b) {
+			b--
+		}
+		if b == previousEnd {
+			b = start
+		}
+		boundaries = append(boundaries, b)
+	}
+	return boundaries
+}
+
+// blankBoundaries returns the start positions of the columns found between the character columns which are blank in all the lines
+func blankBoundaries(lines [][]rune) []int {
+	width := 0
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	boundaries := []int{0}
+	for p := 1; p < width; p++ {
+		if !isBlankColumn(lines, p) && isBlankColumn(lines, p-1) {
+			boundaries = append(boundaries, p)
+		}
+	}
+	return boundaries
+}
+
+// headerNames returns the start and end positions of the words of the header
+func headerNames(header []rune) (starts []int, ends []int) {
+	for p, r := range header {
+		space := unicode.IsSpace(r)
+		switch {
+		case !space && (p == 0 || unicode.IsSpace(header[p-1])):
+			starts = append(starts, p)
+		case space && p > 0 && !unicode.IsSpace(header[p-1]):
+			ends = append(ends, p)
+		}
+	}
+	if len(ends) < len(starts) {
+		ends = append(ends, len(header))
+	}
+	return starts, ends
+}
+
+// hasValueStarts reports whether a value of any line starts between the positions. A negative end is the end of the line
+func hasValueStarts(lines [][]rune, start, end int) bool {
+	for _, line := range lines {
+		for p := start; p < len(line) && (end < 0 || p < end); p++ {
+			if !unicode.IsSpace(line[p]) && (p == 0 || unicode.IsSpace(line[p-1])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// crossesBoundary reports whether any line has a value spanning the position, which would be split by a boundary there
+func crossesBoundary(lines [][]rune, p int) bool {
+	for _, line := range lines {
+		if p < len(line) && !unicode.IsSpace(line[p-1]) && !unicode.IsSpace(line[p]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlankColumn(lines [][]rune, p int) bool {
+	for _, line := range lines {
+		if p < len(line) && !unicode.IsSpace(line[p]) {
+			return false
+		}
+	}
+	return true
+}
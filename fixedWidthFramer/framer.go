@@ -0,0 +1,181 @@
+package fixedWidthFramer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
+	"github.com/yesoreyeram/grafana-framer/gframer"
+)
+
+type FixedWidthFramerOptions struct {
+	FrameName  string
+	Columns    []gframer.ColumnSelector
+	Boundaries []int                 // start positions of the columns in characters, starting from 0. Inferred from the spacing of the header when not set
+	NoHeaders  bool                  // fields are named by their position starting from 1. Boundaries are inferred from the blank character columns of the lines
+	Comment    string                // prefix of the lines to ignore, such as #
+	FieldOrder gframer.FieldOrder    // `alphabetical` | `source` | `columns`
+	Strict     bool                  // fail when a value can't be converted to the column type instead of setting null
+	TimeZone   string                // default IANA time zone name of the timestamps without zone information
+	DateOrder  framerUtils.DateOrder // `MDY` | `DMY` | `YMD`. default order of the ambiguous dates such as 01/02/2006
+	InferTypes bool                  // detect the number, boolean and timestamp columns instead of returning every column as string. Types given by Columns take precedence
+}
+
+const (
+	contextCheckInterval = 1024          // number of lines read between the checks of the context cancellation
+	inferLines           = 100           // number of lines after the header used to infer the boundaries
+	maxLineSize          = 1024 * 1024   // longest line supported
+	format               = "fixed width" // format of the invalid input errors
+)
+
+// FixedWidthStringToFrame frames the column aligned text such as the output of `df -h` or `kubectl get`
+func FixedWidthStringToFrame(text string, options FixedWidthFramerOptions) (frame *data.Frame, err error) {
+	if strings.TrimSpace(text) == "" {
+		return frame, gframer.ErrEmptyInput
+	}
+	return FixedWidthReaderToFrame(context.Background(), strings.NewReader(text), options)
+}
+
+// FixedWidthReaderToFrame reads the column aligned text one line at a time and appends the values straight to the typed fields of the frame.
+// Only the first lines are kept to infer the boundaries. Empty lines, comments and the line of dashes under the header are ignored.
+// Reading stops with the context error when the context is cancelled
+func FixedWidthReaderToFrame(ctx context.Context, reader io.Reader, options FixedWidthFramerOptions) (frame *data.Frame, err error) {
+	if err := validateBoundaries(options.Boundaries); err != nil {
+		return frame, err
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	var header []rune
+	var lines [][]rune
+	var builder *gframer.FrameBuilder
+	var boundaries []int
+	var row []interface{}
+	afterHeader := false
+	for count := 0; scanner.Scan(); count++ {
+		if count%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return frame, err
+			}
+		}
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || (options.Comment != "" && strings.HasPrefix(line, options.Comment)) {
+			continue
+		}
+		if !options.NoHeaders && header == nil {
+			header, afterHeader = []rune(line), true
+			continue
+		}
+		if afterHeader && isSeparatorLine(line) {
+			afterHeader = false
+			continue
+		}
+		afterHeader = false
+		if builder != nil {
+			row = splitLine([]rune(line), boundaries, row)
+			if err := builder.AppendRow(row); err != nil {
+				return frame, err
+			}
+			continue
+		}
+		if lines = append(lines, []rune(line)); len(options.Boundaries) == 0 && len(lines) < inferLines {
+			continue
+		}
+		if builder, boundaries, err = newFrameBuilder(header, lines, options); err != nil {
+			return frame, err
+		}
+		if row, err = appendLines(builder, lines, boundaries); err != nil {
+			return frame, err
+		}
+		lines = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return frame, &gframer.ErrInvalidInput{Format: format, Err: err}
+	}
+	if builder == nil {
+		if header == nil && len(lines) == 0 {
+			return frame, gframer.ErrEmptyInput
+		}
+		if builder, boundaries, err = newFrameBuilder(header, lines, options); err != nil {
+			return frame, err
+		}
+		if _, err = appendLines(builder, lines, boundaries); err != nil {
+			return frame, err
+		}
+	}
+	return builder.Frame()
+}
+
+// newFrameBuilder creates the builder of the fields found in the header, or in the given lines without header
+func newFrameBuilder(header []rune, lines [][]rune, options FixedWidthFramerOptions) (*gframer.FrameBuilder, []int, error) {
+	boundaries := options.Boundaries
+	switch {
+	case len(boundaries) > 0:
+	case options.NoHeaders:
+		boundaries = blankBoundaries(lines)
+	default:
+		boundaries = inferBoundaries(header, lines)
+	}
+	names := make([]string, len(boundaries))
+	values := splitLine(header, boundaries, nil)
+	for idx := range names {
+		names[idx] = fmt.Sprintf("%d", idx+1)
+		if options.NoHeaders {
+			continue
+		}
+		if name := values[idx].(string); name != "" {
+			names[idx] = name
+		}
+		for _, col := range options.Columns {
+			if col.Selector == names[idx] && col.Alias != "" {
+				names[idx] = col.Alias
+			}
+		}
+	}
+	builder, err := gframer.NewFrameBuilder(options.FrameName, names, gframer.FramerOptions{
+		FrameName:  options.FrameName,
+		Columns:    options.Columns,
+		FieldOrder: options.FieldOrder,
+		Strict:     options.Strict,
+		TimeZone:   options.TimeZone,
+		DateOrder:  options.DateOrder,
+		InferTypes: options.InferTypes,
+	})
+	return builder, boundaries, err
+}
+
+func appendLines(builder *gframer.FrameBuilder, lines [][]rune, boundaries []int) (row []interface{}, err error) {
+	for _, line := range lines {
+		row = splitLine(line, boundaries, row)
+		if err = builder.AppendRow(row); err != nil {
+			return row, err
+		}
+	}
+	return row, nil
+}
+
+// splitLine returns the trimmed values of the line between the boundaries. The last column extends to the end of the line.
+// The row is reused when given
+func splitLine(line []rune, boundaries []int, row []interface{}) []interface{} {
+	row = row[:0]
+	for idx, start := range boundaries {
+		end := len(line)
+		if idx+1 < len(boundaries) && boundaries[idx+1] < end {
+			end = boundaries[idx+1]
+		}
+		value := ""
+		if start < end {
+			value = strings.TrimSpace(string(line[start:end]))
+		}
+		row = append(row, value)
+	}
+	return row
+}
+
+// isSeparatorLine reports whether the line is made of dashes, such as the line under the header of the sql query results
+func isSeparatorLine(line string) bool {
+	return strings.ContainsAny(line, "-=") && strings.Trim(line, "-=+| \t") == ""
+}
@@ -0,0 +1,137 @@
+package fixedWidthFramer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/gframer"
+)
+
+func TestFixedWidthStringToFrame(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		options   FixedWidthFramerOptions
+		wantError error
+	}{
+		{
+			name:      "empty text should return error",
+			wantError: gframer.ErrEmptyInput,
+		},
+		{
+			name:      "invalid boundaries should return error",
+			text:      "a b\n1 2",
+			options:   FixedWidthFramerOptions{Boundaries: []int{0, 5, 3}},
+			wantError: ErrInvalidBoundaries,
+		},
+		{
+			name: "df",
+			text: strings.Join([]string{
+				`Filesystem      Size  Used Avail Use% Mounted on`,
+				`/dev/sda1        50G   20G   28G  42% /`,
+				`tmpfs          1000M     0 1000M   0% /dev/shm`,
+				`/dev/sdb1       1.8T  1.2T  512G  71% /mnt/data`,
+			}, "\n"),
+			options: FixedWidthFramerOptions{FieldOrder: gframer.FieldOrderSource},
+		},
+		{
+			name: "kubectl get pods",
+			text: strings.Join([]string{
+				`NAME                     READY   STATUS             RESTARTS   AGE`,
+				`web-6d4cf56db6-8xk2p     1/1     Running            0          3d`,
+				`worker-7c9b8d7f5-q2lzr   0/1     CrashLoopBackOff   12         5h`,
+			}, "\n"),
+			options: FixedWidthFramerOptions{FrameName: "pods", InferTypes: true, FieldOrder: gframer.FieldOrderSource, Columns: []gframer.ColumnSelector{
+				{Selector: "NAME", Alias: "name"},
+				{Selector: "STATUS", Alias: "status"},
+				{Selector: "RESTARTS", Alias: "restarts"},
+			}},
+		},
+		{
+			name: "explicit boundaries",
+			text: strings.Join([]string{
+				`# generated by the mainframe job`,
+				`ACCOUNT   BALANCE   DATE`,
+				`0001234     150.25  2022-03-01`,
+				`0005678    -300.00  2022-03-02`,
+			}, "\n"),
+			options: FixedWidthFramerOptions{Comment: "#", Boundaries: []int{0, 8, 18}, Columns: []gframer.ColumnSelector{
+				{Selector: "ACCOUNT", Type: "string"},
+				{Selector: "BALANCE", Type: "number"},
+				{Selector: "DATE", Type: "timestamp"},
+			}},
+		},
+		{
+			name: "header separator line",
+			text: strings.Join([]string{
+				`ID  NAME   SCORE`,
+				`--  -----  -----`,
+				` 1  alice    9.5`,
+				` 2  bob    12.25`,
+			}, "\n"),
+			options: FixedWidthFramerOptions{FieldOrder: gframer.FieldOrderSource, Columns: []gframer.ColumnSelector{
+				{Selector: "ID", Alias: "id", Type: "int64"},
+				{Selector: "NAME", Alias: "name"},
+				{Selector: "SCORE", Alias: "score", Type: "number"},
+			}},
+		},
+		{
+			name: "no headers",
+			text: strings.Join([]string{
+				`2022-03-01  host-a   12`,
+				`2022-03-02  host-b  345`,
+			}, "\n"),
+			options: FixedWidthFramerOptions{NoHeaders: true, InferTypes: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFrame, err := FixedWidthStringToFrame(tt.text, tt.options)
+			if tt.wantError != nil {
+				require.NotNil(t, err)
+				assert.ErrorIs(t, err, tt.wantError)
+				return
+			}
+			require.Nil(t, err)
+			require.NotNil(t, gotFrame)
+			goldenFileName := strings.Replace(t.Name(), "TestFixedWidthStringToFrame/", "", 1)
+			experimental.CheckGoldenJSONFrame(t, "testdata", goldenFileName, gotFrame, false)
+		})
+	}
+}
+
+func TestFixedWidthReaderToFrame(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := FixedWidthReaderToFrame(ctx, strings.NewReader("a  b\n1  2"), FixedWidthFramerOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestInferBoundaries(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		lines  []string
+		want   []int
+	}{
+		{name: "left aligned", header: "NAME   AGE", lines: []string{"foo    3d"}, want: []int{0, 7}},
+		{name: "right aligned values wider than the name", header: "name  size", lines: []string{"a    1000G", "b       1G"}, want: []int{0, 5}},
+		{name: "names with single space", header: "Use% Mounted on", lines: []string{" 42% /"}, want: []int{0, 5}},
+		{name: "single spaced names with values", header: "Used Avail", lines: []string{" 20G   28G"}, want: []int{0, 5}},
+		{name: "long value below single spaced names", header: "Mounted on", lines: []string{"/", "/mnt/data"}, want: []int{0}},
+		{name: "header only", header: "a  b", want: []int{0, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([][]rune, len(tt.lines))
+			for i, l := range tt.lines {
+				lines[i] = []rune(l)
+			}
+			require.Equal(t, tt.want, inferBoundaries([]rune(tt.header), lines))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package framerUtils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDuration is returned when the input is not a go, iso-8601 or clock duration
+var ErrInvalidDuration = errors.New("invalid duration")
+
+// ParseDuration parses the go durations such as 1h30m, the iso-8601 durations such as PT15M or P1DT2H
+// and the clock durations such as 00:01:23.5, 01:23 (minutes and seconds) or 1.02:03:04 (days, hours, minutes and seconds).
+// Years and months of iso-8601 durations are not supported as their length varies
+func ParseDuration(input string) (time.Duration, error) {
+	value := strings.TrimSpace(input)
+	if value == "" {
+		return 0, fmt.Errorf("%w %q", ErrInvalidDuration, input)
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, nil
+	}
+	negative := false
+	if value[0] == '-' || value[0] == '+' {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasPrefix(strings.ToUpper(value), "P"):
+		d, err = parseISODuration(strings.ToUpper(value[1:]))
+	case strings.Contains(value, ":"):
+		d, err = parseClockDuration(value)
+	default:
+		err = ErrInvalidDuration
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidDuration, input)
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseISODuration parses the iso-8601 duration without the leading P, such as 1DT2H30M or T0.5S
+func parseISODuration(value string) (time.Duration, error) {
+	if value == "" || value == "T" {
+		return 0, ErrInvalidDuration
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	timeUnits := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	var total float64
+	number := ""
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= '0' && c <= '9' || c == '.' || c == ',':
+			number += string(c)
+		case c == 'T':
+			if number != "" {
+				return 0, ErrInvalidDuration
+			}
+			units, timeUnits = timeUnits, nil
+		default:
+			unit, ok := units[c]
+			if !ok || number == "" {
+				return 0, ErrInvalidDuration
+			}
+			f, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+			if err != nil {
+				return 0, ErrInvalidDuration
+			}
+			total += f * float64(unit)
+			number = ""
+		}
+	}
+	if number != "" || math.Abs(total) > math.MaxInt64 {
+		return 0, ErrInvalidDuration
+	}
+	return time.Duration(math.Round(total)), nil
+}
+
+// parseClockDuration parses [days.]hours:minutes:seconds[.fraction] or minutes:seconds[.fraction]
+func parseClockDuration(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, ErrInvalidDuration
+	}
+	var days, hours int64
+	if len(parts) == 3 {
+		hourPart := parts[0]
+		if idx := strings.Index(hourPart, "."); idx >= 0 {
+			d, err := strconv.ParseInt(hourPart[:idx], 10, 64)
+			if err != nil {
+				return 0, ErrInvalidDuration
+			}
+			days, hourPart = d, hourPart[idx+1:]
+		}
+		h, err := strconv.ParseInt(hourPart, 10, 64)
+		if err != nil || h < 0 {
+			return 0, ErrInvalidDuration
+		}
+		hours = h
+		parts = parts[1:]
+	}
+	minutes, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || minutes < 0 || (hours > 0 || days > 0) && minutes > 59 {
+		return 0, ErrInvalidDuration
+	}
+	seconds, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || seconds < 0 || seconds >= 60 || strings.ContainsAny(parts[1], "eE+-") {
+		return 0, ErrInvalidDuration
+	}
+	d := time.Duration(days)*24*time.Hour + time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	return d + time.Duration(math.Round(seconds*float64(time.Second))), nil
+}
@@ -0,0 +1,48 @@
+package framerUtils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "1h30m", want: 90 * time.Minute},
+		{input: "-1.5s", want: -1500 * time.Millisecond},
+		{input: "PT15M", want: 15 * time.Minute},
+		{input: "P1DT2H", want: 26 * time.Hour},
+		{input: "P2W", want: 14 * 24 * time.Hour},
+		{input: "PT0,5S", want: 500 * time.Millisecond},
+		{input: "-PT1M30.25S", want: -(90*time.Second + 250*time.Millisecond)},
+		{input: "00:01:23.5", want: 83500 * time.Millisecond},
+		{input: "01:23", want: 83 * time.Second},
+		{input: "1.02:03:04", want: 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{input: "125:00:00", want: 125 * time.Hour},
+		{input: "P1Y", wantErr: true},
+		{input: "P1M", wantErr: true},
+		{input: "PT", wantErr: true},
+		{input: "PT1H2", wantErr: true},
+		{input: "01:61:00", wantErr: true},
+		{input: "00:00:60", wantErr: true},
+		{input: "foo", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := framerUtils.ParseDuration(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, framerUtils.ErrInvalidDuration)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
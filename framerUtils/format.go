@@ -0,0 +1,180 @@
+package framerUtils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefixes of the TimeFormat to choose the style of the layout explicitly. Without prefix, the style is detected from the layout
+const (
+	TimeFormatPrefixMoment   = "moment:"   // YYYY-MM-DD HH:mm:ss
+	TimeFormatPrefixStrftime = "strftime:" // %Y-%m-%d %H:%M:%S
+	TimeFormatPrefixGo       = "go:"       // 2006-01-02 15:04:05
+)
+
+// ErrUnsupportedTimeFormatToken is returned when the moment or strftime layout has a token which can't be converted to go layout
+var ErrUnsupportedTimeFormatToken = errors.New("unsupported time format token")
+
+var momentTokens = map[string]string{
+	"YYYY": "2006",
+	"YY":   "06",
+	"MMMM": "January",
+	"MMM":  "Jan",
+	"MM":   "01",
+	"M":    "1",
+	"DD":   "02",
+	"D":    "2",
+	"dddd": "Monday",
+	"ddd":  "Mon",
+	"HH":   "15",
+	"H":    "15",
+	"hh":   "03",
+	"h":    "3",
+	"mm":   "04",
+	"m":    "4",
+	"ss":   "05",
+	"s":    "5",
+	"A":    "PM",
+	"a":    "pm",
+	"ZZ":   "-0700",
+	"Z":    "-07:00",
+	"z":    "MST",
+	"zz":   "MST",
+}
+
+// momentTokenLetters are the letters of the moment tokens. Runs of these letters which are not in momentTokens are not supported
+const momentTokenLetters = "YMDdHhmsAaZzSQWwEeXxkNGg"
+
+var strftimeTokens = map[string]string{
+	"Y":  "2006",
+	"y":  "06",
+	"m":  "01",
+	"-m": "1",
+	"d":  "02",
+	"-d": "2",
+	"e":  "_2",
+	"H":  "15",
+	"-H": "15",
+	"I":  "03",
+	"-I": "3",
+	"M":  "04",
+	"-M": "4",
+	"S":  "05",
+	"-S": "5",
+	"f":  "000000",
+	"p":  "PM",
+	"b":  "Jan",
+	"h":  "Jan",
+	"B":  "January",
+	"a":  "Mon",
+	"A":  "Monday",
+	"z":  "-0700",
+	"Z":  "MST",
+	"F":  "2006-01-02",
+	"T":  "15:04:05",
+	"R":  "15:04",
+	"D":  "01/02/06",
+	"%":  "%",
+}
+
+// ConvertTimeFormat converts the moment style (YYYY-MM-DD HH:mm:ss) or strftime style (%Y-%m-%d %H:%M:%S) layout to go layout.
+// The style is given by the moment: / strftime: / go: prefix, or detected from the layout. Go layouts are returned as is
+func ConvertTimeFormat(format string) (string, error) {
+	switch {
+	case strings.HasPrefix(format, TimeFormatPrefixMoment):
+		return convertMomentFormat(strings.TrimPrefix(format, TimeFormatPrefixMoment))
+	case strings.HasPrefix(format, TimeFormatPrefixStrftime):
+		return convertStrftimeFormat(strings.TrimPrefix(format, TimeFormatPrefixStrftime))
+	case strings.HasPrefix(format, TimeFormatPrefixGo):
+		return strings.TrimPrefix(format, TimeFormatPrefixGo), nil
+	case strings.Contains(format, "%"):
+		return convertStrftimeFormat(format)
+	case isMomentFormat(format):
+		return convertMomentFormat(format)
+	}
+	return format, nil
+}
+
+// ValidateTimeFormat checks whether the moment or strftime layout can be converted to go layout. Keywords such as auto, excel and yyyymmdd are valid
+func ValidateTimeFormat(format string) error {
+	if IsNumericTimeFormat(format) || strings.ToLower(format) == TimeFormatAuto {
+		return nil
+	}
+	_, err := ConvertTimeFormat(format)
+	return err
+}
+
+// isMomentFormat detects the moment layouts by the tokens which never appear in go layouts
+func isMomentFormat(format string) bool {
+	for _, token := range []string{"YY", "DD", "HH", "hh", "mm", "ss"} {
+		if strings.Contains(format, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func convertMomentFormat(format string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(format); {
+		c := format[i]
+		if c == '[' {
+			// text within brackets is literal
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("%w. unclosed [ in %q", ErrUnsupportedTimeFormatToken, format)
+			}
+			out.WriteString(format[i+1 : i+end])
+			i += end + 1
+			continue
+		}
+		if !strings.ContainsRune(momentTokenLetters, rune(c)) {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		j := i
+		for j < len(format) && format[j] == c {
+			j++
+		}
+		token := format[i:j]
+		switch {
+		case token == "D" && j < len(format) && format[j] == 'o':
+			return "", fmt.Errorf("%w Do in %q", ErrUnsupportedTimeFormatToken, format)
+		case c == 'S' && len(token) <= 9:
+			// fractional seconds. the dot before the token is kept as is
+			out.WriteString(strings.Repeat("0", len(token)))
+		case momentTokens[token] != "":
+			out.WriteString(momentTokens[token])
+		default:
+			return "", fmt.Errorf("%w %s in %q", ErrUnsupportedTimeFormatToken, token, format)
+		}
+		i = j
+	}
+	return out.String(), nil
+}
+
+func convertStrftimeFormat(format string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+		if i+1 >= len(format) {
+			return "", fmt.Errorf("%w %% at the end of %q", ErrUnsupportedTimeFormatToken, format)
+		}
+		token := format[i+1 : i+2]
+		if token == "-" && i+2 < len(format) {
+			token = format[i+1 : i+3]
+		}
+		layout, ok := strftimeTokens[token]
+		if !ok {
+			return "", fmt.Errorf("%w %%%s in %q", ErrUnsupportedTimeFormatToken, token, format)
+		}
+		out.WriteString(layout)
+		i += len(token)
+	}
+	return out.String(), nil
+}
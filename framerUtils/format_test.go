@@ -0,0 +1,62 @@
+package framerUtils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
+)
+
+func TestConvertTimeFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr error
+	}{
+		{format: "2006-01-02 15:04:05", want: "2006-01-02 15:04:05"},
+		{format: "YYYY-MM-DD HH:mm:ss", want: "2006-01-02 15:04:05"},
+		{format: "YYYY-MM-DDTHH:mm:ss.SSSZ", want: "2006-01-02T15:04:05.000-07:00"},
+		{format: "ddd, D MMM YY h:mm A", want: "Mon, 2 Jan 06 3:04 PM"},
+		{format: "DD/MM/YYYY [at] HH:mm", want: "02/01/2006 at 15:04"},
+		{format: "moment:M/D/YYYY", want: "1/2/2006"},
+		{format: "%Y-%m-%d %H:%M:%S", want: "2006-01-02 15:04:05"},
+		{format: "strftime:%d %b %Y %-I:%M %p %z", want: "02 Jan 2006 3:04 PM -0700"},
+		{format: "%F %T.%f", want: "2006-01-02 15:04:05.000000"},
+		{format: "go:2006-01-02", want: "2006-01-02"},
+		{format: "YYYY-MM-DD Do", wantErr: framerUtils.ErrUnsupportedTimeFormatToken},
+		{format: "YYYY-[W]WW", wantErr: framerUtils.ErrUnsupportedTimeFormatToken},
+		{format: "X", want: "X"},
+		{format: "moment:X", wantErr: framerUtils.ErrUnsupportedTimeFormatToken},
+		{format: "%Y-%j", wantErr: framerUtils.ErrUnsupportedTimeFormatToken},
+		{format: "%Y-%m-%", wantErr: framerUtils.ErrUnsupportedTimeFormatToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := framerUtils.ConvertTimeFormat(tt.format)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.ErrorIs(t, framerUtils.ValidateTimeFormat(tt.format), tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetTimeFromStringWithTimeFormatStyles(t *testing.T) {
+	want := time.Date(2022, 3, 1, 15, 30, 0, 0, time.UTC)
+	for input, format := range map[string]string{
+		"01/03/2022 15:30":     "DD/MM/YYYY HH:mm",
+		"2022.03.01 03:30 PM":  "strftime:%Y.%m.%d %I:%M %p",
+		"Tue 1 Mar 2022 15:30": "%a %-d %b %Y %-H:%M",
+	} {
+		got := framerUtils.GetTimeFromString(input, format)
+		require.NotNil(t, got, format)
+		require.Equal(t, want, *got, format)
+	}
+	got, err := framerUtils.GetTimeFromNumber(20220301, "YYYYMMDD", nil)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), got)
+}
@@ -0,0 +1,194 @@
+package framerUtils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateOrder is the order of day, month and year used for the ambiguous dates such as 01/02/2006
+type DateOrder string
+
+const (
+	DateOrderMDY DateOrder = "MDY" // 01/02/2006 is 2nd January. This is the default
+	DateOrderDMY DateOrder = "DMY" // 01/02/2006 is 1st February
+	DateOrderYMD DateOrder = "YMD" // 06/01/02 is 2nd January 2006
+)
+
+// IsValid checks whether the date order is one of the known orders. Empty order is valid and treated as MDY
+func (o DateOrder) IsValid() bool {
+	switch o {
+	case "", DateOrderMDY, DateOrderDMY, DateOrderYMD:
+		return true
+	}
+	return false
+}
+
+// Pseudo layouts of the formats which can't be expressed as go layout
+const (
+	LayoutISOWeek = "iso-week" // 2006-W01-1 or 2006W011. The day of the week is optional
+	LayoutOrdinal = "ordinal"  // 2006-002 or 2006002
+)
+
+var (
+	yearFirstDateFormats  = []string{"2006-01-02", "2006-1-2", "2006/01/02", "2006/1/2", "2006.01.02"}
+	monthFirstDateFormats = []string{"01/02/2006", "1/2/2006", "01-02-2006", "01/02/06", "1/2/06"}
+	dayFirstDateFormats   = []string{"02/01/2006", "2/1/2006", "02-01-2006", "02.01.2006", "2.1.2006", "02/01/06", "2/1/06"}
+	shortYearDateFormats  = []string{"06/01/02", "06-01-02"}
+	monthNameDateFormats  = []string{"02-Jan-2006", "2-Jan-2006", "02 Jan 2006", "2 Jan 2006", "2 January 2006", "Jan 2, 2006", "Jan 2 2006", "January 2, 2006", "January 2 2006"}
+	possibleTimeFormats   = []string{"", "15:04", "15:04:05.999999", "15:04:05.999999Z", "15:04:05.999999Z07:00", "15:04:05.999999 -07:00", "15:04:05 MST", "3:04 PM", "3:04:05 PM", "3:04PM", "3:04:05PM", "3:04 pm", "3:04:05 pm"}
+	possibleFixedLayouts  = []string{time.RFC3339Nano, time.RFC1123, time.RFC1123Z, time.RFC822, time.RFC822Z, time.RFC850, time.ANSIC, time.UnixDate, time.RubyDate}
+	possibleMonthLayouts  = []string{"2006-01", "2006/01", "01-2006", "01/2006", "Jan 2006", "January 2006"}
+)
+
+// layoutsByOrder are the default layouts for each date order, computed once
+var layoutsByOrder = map[DateOrder][]string{
+	DateOrderMDY: buildLayouts(yearFirstDateFormats, monthFirstDateFormats, dayFirstDateFormats, shortYearDateFormats, monthNameDateFormats),
+	DateOrderDMY: buildLayouts(yearFirstDateFormats, dayFirstDateFormats, monthFirstDateFormats, shortYearDateFormats, monthNameDateFormats),
+	DateOrderYMD: buildLayouts(yearFirstDateFormats, shortYearDateFormats, monthFirstDateFormats, dayFirstDateFormats, monthNameDateFormats),
+}
+
+// ambiguousByOrder are, for each date order, the layouts before a default layout which may read the same values with another order of the day, month and year
+var ambiguousByOrder = map[DateOrder]map[string][]string{
+	DateOrderMDY: ambiguousLayouts(layoutsByOrder[DateOrderMDY]),
+	DateOrderDMY: ambiguousLayouts(layoutsByOrder[DateOrderDMY]),
+	DateOrderYMD: ambiguousLayouts(layoutsByOrder[DateOrderYMD]),
+}
+
+// ambiguousLayouts returns, for each layout, the layouts before it with the same shape, such as 02/01/2006 before 01/02/2006 or 2/1/2006
+func ambiguousLayouts(layouts []string) map[string][]string {
+	byShape := map[string][]string{}
+	out := map[string][]string{}
+	for _, l := range layouts {
+		shape := layoutShape(l)
+		if earlier := byShape[shape]; len(earlier) > 0 {
+			out[l] = append([]string(nil), earlier...)
+		}
+		byShape[shape] = append(byShape[shape], l)
+	}
+	return out
+}
+
+// layoutShape replaces the runs of digits of the layout by N, and the four digit years by Y. 01/02/2006 and 2/1/2006 are both N/N/Y.
+// 2006/01/02 is Y/N/N, as the four digit year can't read the values of the other shape
+func layoutShape(layout string) string {
+	var out strings.Builder
+	for i := 0; i < len(layout); {
+		if layout[i] < '0' || layout[i] > '9' {
+			out.WriteByte(layout[i])
+			i++
+			continue
+		}
+		if strings.HasPrefix(layout[i:], "2006") {
+			out.WriteByte('Y')
+			i += len("2006")
+			continue
+		}
+		out.WriteByte('N')
+		for i < len(layout) && layout[i] >= '0' && layout[i] <= '9' {
+			i++
+		}
+	}
+	return out.String()
+}
+
+// defaultLayouts returns the layouts of the date order. The dates of the preferred order are tried before the other ambiguous dates
+func defaultLayouts(order DateOrder) []string {
+	if layouts, ok := layoutsByOrder[order]; ok {
+		return layouts
+	}
+	return layoutsByOrder[DateOrderMDY]
+}
+
+func buildLayouts(dateFormats ...[]string) []string {
+	layouts := []string{"2006"}
+	seen := map[string]bool{"2006": true}
+	add := func(l string) {
+		if !seen[l] {
+			seen[l] = true
+			layouts = append(layouts, l)
+		}
+	}
+	for _, formats := range dateFormats {
+		for _, d := range formats {
+			for _, t := range possibleTimeFormats {
+				add(strings.TrimSpace(fmt.Sprintf("%s %s", d, t)))
+				if t != "" && strings.HasPrefix(d, "2006-") {
+					add(fmt.Sprintf("%sT%s", d, t))
+				}
+			}
+		}
+	}
+	for _, l := range possibleFixedLayouts {
+		add(l)
+	}
+	for _, l := range possibleMonthLayouts {
+		add(l)
+	}
+	return append(layouts, LayoutISOWeek, LayoutOrdinal)
+}
+
+var errLayoutMismatch = errors.New("input doesn't match the layout")
+
+// parseLayout parses the input using the go layout or the pseudo layout
+func parseLayout(layout string, input string, loc *time.Location) (time.Time, error) {
+	switch layout {
+	case LayoutISOWeek:
+		return parseISOWeek(input, loc)
+	case LayoutOrdinal:
+		return parseOrdinal(input, loc)
+	}
+	return time.ParseInLocation(layout, input, loc)
+}
+
+// parseISOWeek parses the iso week dates such as 2022-W09-2, 2022W092 or 2022-W09 (monday of the week)
+func parseISOWeek(input string, loc *time.Location) (time.Time, error) {
+	value := strings.ReplaceAll(input, "-", "")
+	if len(value) != 7 && len(value) != 8 || value[4] != 'W' {
+		return time.Time{}, errLayoutMismatch
+	}
+	year, err := strconv.Atoi(value[:4])
+	if err != nil {
+		return time.Time{}, errLayoutMismatch
+	}
+	week, err := strconv.Atoi(value[5:7])
+	if err != nil || week < 1 || week > 53 {
+		return time.Time{}, errLayoutMismatch
+	}
+	day := 1
+	if len(value) == 8 {
+		if day, err = strconv.Atoi(value[7:]); err != nil || day < 1 || day > 7 {
+			return time.Time{}, errLayoutMismatch
+		}
+	}
+	// 4th January is always in the first week
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := 4 - (int(jan4.Weekday())+6)%7
+	t := time.Date(year, time.January, monday+(week-1)*7+day-1, 0, 0, 0, 0, loc)
+	if _, w := t.ISOWeek(); w != week {
+		return time.Time{}, errLayoutMismatch
+	}
+	return t, nil
+}
+
+// parseOrdinal parses the ordinal dates such as 2022-060 or 2022060
+func parseOrdinal(input string, loc *time.Location) (time.Time, error) {
+	value := input
+	if len(value) == 8 && value[4] == '-' {
+		value = value[:4] + value[5:]
+	}
+	if len(value) != 7 {
+		return time.Time{}, errLayoutMismatch
+	}
+	year, err := strconv.Atoi(value[:4])
+	if err != nil || strings.ContainsAny(value, "+-") {
+		return time.Time{}, errLayoutMismatch
+	}
+	day, err := strconv.Atoi(value[4:])
+	if err != nil || day < 1 || day > time.Date(year, time.December, 31, 0, 0, 0, 0, loc).YearDay() {
+		return time.Time{}, errLayoutMismatch
+	}
+	return time.Date(year, time.January, day, 0, 0, 0, 0, loc), nil
+}
@@ -0,0 +1,62 @@
+package framerUtils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidNumber is returned when the input can't be parsed as number
+var ErrInvalidNumber = errors.New("invalid number")
+
+// NumberFormat describes the numbers formatted for humans, such as the numbers exported from spreadsheets
+type NumberFormat struct {
+	DecimalSeparator   string // `,` for 1.234,56. Defaults to `.`
+	ThousandsSeparator string // `,` for 1,234.56. Spaces also remove the non breaking spaces
+	StripCurrency      bool   // remove the currency symbols such as $, € and £
+	StripPercent       bool   // remove the percent sign. 45% becomes 45
+	AccountingNegative bool   // numbers in parentheses such as (300) are negative
+}
+
+// ParseNumber parses the number using the format. Leading and trailing spaces are ignored
+func ParseNumber(input string, format NumberFormat) (float64, error) {
+	value := strings.TrimSpace(input)
+	negative := false
+	if format.AccountingNegative && strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	if format.StripCurrency {
+		value = strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Sc, r) {
+				return -1
+			}
+			return r
+		}, value)
+	}
+	if format.StripPercent {
+		value = strings.ReplaceAll(value, "%", "")
+	}
+	if format.ThousandsSeparator != "" {
+		value = strings.ReplaceAll(value, format.ThousandsSeparator, "")
+		if strings.TrimSpace(format.ThousandsSeparator) == "" {
+			value = strings.NewReplacer("\u00a0", "", "\u202f", "").Replace(value)
+		}
+	}
+	if format.DecimalSeparator != "" && format.DecimalSeparator != "." {
+		if strings.Contains(value, ".") {
+			return 0, fmt.Errorf("%w %q", ErrInvalidNumber, input)
+		}
+		value = strings.Replace(value, format.DecimalSeparator, ".", 1)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidNumber, input)
+	}
+	if negative {
+		f = -f
+	}
+	return f, nil
+}
@@ -0,0 +1,48 @@
+package framerUtils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
+)
+
+func TestParseNumber(t *testing.T) {
+	spreadsheet := framerUtils.NumberFormat{ThousandsSeparator: ",", StripCurrency: true, StripPercent: true, AccountingNegative: true}
+	european := framerUtils.NumberFormat{DecimalSeparator: ",", ThousandsSeparator: "."}
+	tests := []struct {
+		input   string
+		format  framerUtils.NumberFormat
+		want    float64
+		wantErr bool
+	}{
+		{input: "1e3 ", want: 1000},
+		{input: " 12.5", want: 12.5},
+		{input: "1,234.56", wantErr: true},
+		{input: "1,234.56", format: spreadsheet, want: 1234.56},
+		{input: "$1,200", format: spreadsheet, want: 1200},
+		{input: "-$1,200", format: spreadsheet, want: -1200},
+		{input: "€ 99", format: spreadsheet, want: 99},
+		{input: "45%", format: spreadsheet, want: 45},
+		{input: "(300)", format: spreadsheet, want: -300},
+		{input: "($1,234.50)", format: spreadsheet, want: -1234.5},
+		{input: "(300)", wantErr: true},
+		{input: "1.234,56", format: european, want: 1234.56},
+		{input: "1.234.567", format: european, want: 1234567},
+		{input: "1 234,5", format: framerUtils.NumberFormat{DecimalSeparator: ",", ThousandsSeparator: " "}, want: 1234.5},
+		{input: "1\u00a0234,5", format: framerUtils.NumberFormat{DecimalSeparator: ",", ThousandsSeparator: " "}, want: 1234.5},
+		{input: "1.5", format: framerUtils.NumberFormat{DecimalSeparator: ","}, wantErr: true},
+		{input: "foo", format: spreadsheet, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := framerUtils.ParseNumber(tt.input, tt.format)
+			if tt.wantErr {
+				require.ErrorIs(t, err, framerUtils.ErrInvalidNumber)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,35 +1,212 @@
 package framerUtils
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-var possibleDateFormats = []string{"2006-01-02", "2006-1-2", "2006/01/02", "2006/1/2", "01/02/2006", "1/2/2006"}
-var possibleDateTimeSeparators = []string{"T", " "}
-var possibleTimeFormats = []string{"", "15:04", "15:04:05.999999", "15:04:05.999999Z", "15:04:05.999999 -07:00", "15:04:05 MST"}
+// Keywords of the TimeFormat for the numeric timestamps. Compact numeric dates can also be given as yyyy, yyyymm, yyyymmdd, yyyymmddhh, yyyymmddhhmm and yyyymmddhhmmss
+const (
+	TimeFormatAuto   = "auto"   // compact numeric dates are detected from the number of digits
+	TimeFormatExcel  = "excel"  // days since 1899-12-30 as used by spreadsheets. fraction is the time of the day
+	TimeFormatJulian = "julian" // julian day number. fraction is the time of the day in UTC
+)
+
+var numericLayouts = map[string]string{
+	"yyyy":           "2006",
+	"yyyymm":         "200601",
+	"yyyymmdd":       "20060102",
+	"yyyymmddhh":     "2006010215",
+	"yyyymmddhhmm":   "200601021504",
+	"yyyymmddhhmmss": "20060102150405",
+}
+
+// compactLayouts are the layouts of the compact numeric dates by number of digits. 10 digits are left out as they clash with the epoch seconds
+var compactLayouts = map[int]string{4: "2006", 6: "200601", 8: "20060102", 12: "200601021504", 14: "20060102150405"}
+
+// julianUnixEpoch is the julian day of 1970-01-01T00:00:00Z
+const julianUnixEpoch = 2440587.5
+
+// ErrInvalidNumericTime is returned when the number can't be converted to time using the time format
+var ErrInvalidNumericTime = errors.New("invalid numeric timestamp")
+
+// IsNumericTimeFormat checks whether the time format is one of the keywords of the numeric timestamps
+func IsNumericTimeFormat(timeFormat string) bool {
+	switch strings.ToLower(timeFormat) {
+	case TimeFormatExcel, TimeFormatJulian:
+		return true
+	}
+	_, ok := numericLayouts[strings.ToLower(timeFormat)]
+	return ok
+}
+
+// GetTimeFromNumber converts numeric timestamp such as 20220301 (yyyymmdd), 44621.5 (excel) or 2459640.5 (julian) to time.
+// timeFormat can be one of the keywords or a go layout such as 20060102. Without timeFormat the compact numeric dates are detected from the number of digits
+func GetTimeFromNumber(input float64, timeFormat string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if math.IsNaN(input) || math.IsInf(input, 0) {
+		return time.Time{}, fmt.Errorf("%w %v", ErrInvalidNumericTime, input)
+	}
+	switch strings.ToLower(timeFormat) {
+	case "", TimeFormatAuto:
+		digits := strconv.FormatFloat(input, 'f', -1, 64)
+		layout, ok := compactLayouts[len(digits)]
+		if !ok || input != math.Trunc(input) {
+			return time.Time{}, fmt.Errorf("%w %s. unable to detect the layout, time format is required", ErrInvalidNumericTime, digits)
+		}
+		return time.ParseInLocation(layout, digits, loc)
+	case TimeFormatExcel:
+		days := math.Floor(input)
+		nanoseconds := math.Round((input - days) * float64(24*time.Hour))
+		return time.Date(1899, 12, 30+int(days), 0, 0, 0, int(nanoseconds), loc), nil
+	case TimeFormatJulian:
+		seconds := (input - julianUnixEpoch) * 86400
+		whole := math.Floor(seconds)
+		return time.Unix(int64(whole), int64(math.Round((seconds-whole)*1e9))).UTC(), nil
+	}
+	timeFormat = resolveLayout(timeFormat)
+	if input != math.Trunc(input) {
+		return time.Time{}, fmt.Errorf("%w %v. fractional values are not supported by the layout %s", ErrInvalidNumericTime, input, timeFormat)
+	}
+	return time.ParseInLocation(timeFormat, strconv.FormatFloat(input, 'f', 0, 64), loc)
+}
+
+// resolveLayout returns the go layout of the numeric layout keywords, moment and strftime layouts. Invalid layouts are returned as is
+func resolveLayout(timeFormat string) string {
+	if layout, ok := numericLayouts[strings.ToLower(timeFormat)]; ok {
+		return layout
+	}
+	if layout, err := ConvertTimeFormat(timeFormat); err == nil {
+		return layout
+	}
+	return timeFormat
+}
+
+var locations sync.Map
+
+// LoadLocation returns the time zone of the IANA name such as `Europe/London`. Empty name is treated as UTC. The loaded locations are cached
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
 
 func GetTimeFromString(input string, timeFormat string) *time.Time {
-	if timeFormat == "auto" {
+	return GetTimeFromStringInLocation(input, timeFormat, time.UTC)
+}
+
+// GetTimeFromStringInLocation parses the input like GetTimeFromString, but the values without time zone information are treated as time in the given location
+func GetTimeFromStringInLocation(input string, timeFormat string, loc *time.Location) *time.Time {
+	return NewTimeParser(timeFormat, loc, DateOrderMDY).Parse(input)
+}
+
+// TimeParser parses the time strings of a column. The layout that succeeded last is tried first, so that the columns
+// with consistent layout are parsed with single attempt per value. The layout is reused only when the value can't be read
+// with the timeFormat or with a layout of the preferred date order, so that the result doesn't depend on the order of the values.
+// TimeParser is not safe for concurrent use
+type TimeParser struct {
+	timeFormat string
+	loc        *time.Location
+	layouts    []string
+	ambiguous  map[string][]string // preferred layouts of the same shape, for each default layout
+	last       string
+}
+
+// NewTimeParser creates the parser of the time strings. timeFormat is tried first, before RFC3339 and the default layouts.
+// The values without time zone information are treated as time in the given location, UTC when nil. The date order decides which of the ambiguous dates such as 01/02/2006 are tried first
+func NewTimeParser(timeFormat string, loc *time.Location, order DateOrder) *TimeParser {
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch strings.ToLower(timeFormat) {
+	case TimeFormatAuto, TimeFormatExcel, TimeFormatJulian:
 		timeFormat = ""
 	}
-	possibleLayouts := []string{time.RFC3339, timeFormat, "2006"}
-	for _, d := range possibleDateFormats {
-		for _, t := range possibleTimeFormats {
-			for _, s := range possibleDateTimeSeparators {
-				l := strings.TrimSpace(fmt.Sprintf("%s%s%s", d, s, t))
-				if l != "" && !strings.HasSuffix(l, " T") {
-					possibleLayouts = append(possibleLayouts, l)
+	ambiguous, ok := ambiguousByOrder[order]
+	if !ok {
+		ambiguous = ambiguousByOrder[DateOrderMDY]
+	}
+	return &TimeParser{timeFormat: resolveLayout(timeFormat), loc: loc, layouts: defaultLayouts(order), ambiguous: ambiguous}
+}
+
+// Parse returns the time of the input, nil when none of the layouts match
+func (p *TimeParser) Parse(input string) *time.Time {
+	if p.last != "" && (p.timeFormat == "" || p.last == p.timeFormat) {
+		if p.last != p.timeFormat {
+			for _, layout := range p.ambiguous[p.last] {
+				if t, ok := p.tryLayout(layout, input); ok {
+					return t
 				}
 			}
 		}
-	}
-	possibleLayouts = append(possibleLayouts, "2006-01", "2006/01", "01-2006", "01/2006")
-	for _, layout := range possibleLayouts {
-		if t, err := time.Parse(layout, input); err == nil && layout != "" {
+		if t, err := parseLayout(p.last, input, p.loc); err == nil {
 			return &t
 		}
 	}
+	if p.timeFormat != "" {
+		if t, ok := p.tryLayout(p.timeFormat, input); ok {
+			return t
+		}
+	}
+	if t, ok := p.tryLayout(time.RFC3339, input); ok {
+		return t
+	}
+	for _, layout := range p.layouts {
+		if t, ok := p.tryLayout(layout, input); ok {
+			return t
+		}
+	}
 	return nil
 }
+
+// Layout returns the layout that succeeded last
+func (p *TimeParser) Layout() string {
+	return p.last
+}
+
+func (p *TimeParser) tryLayout(layout string, input string) (*time.Time, bool) {
+	t, err := parseLayout(layout, input, p.loc)
+	if err != nil {
+		return nil, false
+	}
+	p.last = layout
+	return &t, true
+}
+
+// DetectLayout returns the first layout, among RFC3339 and the default layouts, that parses all the non empty values.
+// Ambiguous dates are resolved using all the values, for example 01/02/2006 layout is returned for 03/01/2022 and 03/13/2022 but 02/01/2006 for 03/01/2022 and 13/01/2022
+func DetectLayout(values []string) (string, bool) {
+	for _, layout := range append([]string{time.RFC3339}, defaultLayouts(DateOrderMDY)...) {
+		matched := false
+		for _, v := range values {
+			if v == "" {
+				continue
+			}
+			if _, err := parseLayout(layout, v, time.UTC); err != nil {
+				matched = false
+				break
+			}
+			matched = true
+		}
+		if matched {
+			return layout, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,235 @@
+package gframer
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameBuilder builds the frame row by row, for the framers which read the rows one at a time such as the csv reader.
+// The values are appended to typed fields as they come, so that the memory is proportional to the frame:
+//   - the values of the columns with a converter are converted as they are appended
+//   - the columns without type, and the columns of the converters which need all the values of the column (ColumnConverter),
+//     are kept as string fields while all their values are strings, and converted when the frame is built.
+//     Columns with other values are kept as is until then
+//
+// The frame is the same as the one ToDataFrame builds from the rows given as objects
+type FrameBuilder struct {
+	name    string
+	options FramerOptions
+	columns []*builderColumn // in the order of the frame fields
+	rows    int
+}
+
+type builderColumn struct {
+	name      string
+	index     int // index of the value in the appended rows
+	seen      bool
+	column    ColumnSelector
+	converter Converter // nil when the column type is inferred
+	deferred  bool      // the column converter is created from all the values of the column when the frame is built
+	field     *data.Field
+	failures  *conversionFailures
+	kinds     columnType    // kinds of the values appended to the string field of the column without type
+	values    []interface{} // values of the columns kept as strings which are not all strings
+}
+
+// NewFrameBuilder creates the builder of the rows with the given field names. The options are applied the same way
+// ToDataFrame applies them to the objects with those keys. Duplicate names use the last value of the row
+func NewFrameBuilder(name string, fieldNames []string, options FramerOptions) (*FrameBuilder, error) {
+	options, err := applyColumnDefaults(options)
+	if err != nil {
+		return nil, err
+	}
+	b := &FrameBuilder{name: name, options: options}
+	indexes := map[string]int{}
+	names := []string{}
+	for idx, n := range fieldNames {
+		if _, ok := indexes[n]; !ok {
+			names = append(names, n)
+		}
+		indexes[n] = idx
+	}
+	for _, n := range orderFieldNames(names, options) {
+		if len(options.Columns) == 0 {
+			b.columns = append(b.columns, newUntypedBuilderColumn(n, indexes[n], ColumnSelector{}))
+			continue
+		}
+		idx := columnIndex(n, options)
+		if idx < 0 {
+			continue
+		}
+		c := options.Columns[idx]
+		converter, ok := GetConverter(c.Type)
+		if !ok {
+			b.columns = append(b.columns, newUntypedBuilderColumn(n, indexes[n], c))
+			continue
+		}
+		if _, ok := converter.(ColumnConverter); ok {
+			bc := newUntypedBuilderColumn(n, indexes[n], c)
+			bc.converter, bc.deferred = converter, true
+			b.columns = append(b.columns, bc)
+			continue
+		}
+		bc := &builderColumn{name: n, index: indexes[n], column: c, converter: converter}
+		bc.newField()
+		b.columns = append(b.columns, bc)
+	}
+	return b, nil
+}
+
+func newUntypedBuilderColumn(name string, index int, column ColumnSelector) *builderColumn {
+	field := data.NewFieldFromFieldType(data.FieldTypeNullableString, 0)
+	field.Name = name
+	return &builderColumn{name: name, index: index, column: column, field: field, kinds: columnType{fieldType: data.FieldTypeNullableString}}
+}
+
+// newField creates the field of the converter
+func (c *builderColumn) newField() {
+	c.field = data.NewFieldFromFieldType(c.converter.FieldType(), 0)
+	c.field.Name = c.name
+	if fc, ok := c.converter.(FieldConfigConverter); ok {
+		c.field.Config = fc.FieldConfig()
+	}
+	c.failures = newConversionFailures(c.name, c.converter.Name())
+}
+
+// AppendRow adds the row. Missing values at the end of the row are set to null.
+// In strict mode, the conversion failure is returned as ErrConversion
+func (b *FrameBuilder) AppendRow(values []interface{}) error {
+	row := b.rows
+	b.rows++
+	for _, c := range b.columns {
+		var value interface{}
+		if c.index < len(values) {
+			value = values[c.index]
+			c.seen = true
+		}
+		if c.converter == nil || c.deferred {
+			c.appendUntyped(value)
+			continue
+		}
+		if err := b.appendConverted(c, row, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendUntyped appends the value to the string field of the column. The values appended so far are kept as is instead, once a value isn't a string
+func (c *builderColumn) appendUntyped(value interface{}) {
+	if c.field == nil {
+		c.values = append(c.values, value)
+		return
+	}
+	s, ok := value.(string)
+	if !ok && value != nil {
+		c.values = fieldValues(c.field)
+		c.values = append(c.values, value)
+		c.field = nil
+		return
+	}
+	if !c.deferred {
+		if kind := getValueKind(value); kind != valueKindNull && !c.kinds.has(kind) {
+			c.kinds.kinds = append(c.kinds.kinds, kind)
+		}
+	}
+	if value == nil {
+		c.field.Append(nil)
+		return
+	}
+	c.field.Append(&s)
+}
+
+// convertColumn creates the column converter from all the values of the column and converts them
+func (b *FrameBuilder) convertColumn(c *builderColumn) error {
+	values := c.values
+	if c.field != nil {
+		values = fieldValues(c.field)
+	}
+	c.converter = c.converter.(ColumnConverter).ForColumn(values, c.column)
+	c.newField()
+	c.values = nil
+	for row, value := range values {
+		if err := b.appendConverted(c, row, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *FrameBuilder) appendConverted(c *builderColumn, row int, value interface{}) error {
+	if value == nil {
+		c.field.Append(nil)
+		return nil
+	}
+	out, err := c.converter.Convert(value, c.column)
+	if err != nil {
+		c.failures.add(row, value, err)
+		c.field.Append(nil)
+		if b.options.Strict && c.failures.count > 0 {
+			return c.failures.err()
+		}
+		return nil
+	}
+	c.field.Append(ToPointer(out))
+	return nil
+}
+
+// Frame builds the frame of the appended rows
+func (b *FrameBuilder) Frame() (*data.Frame, error) {
+	frame := data.NewFrame(b.name)
+	if b.options.ExecutedQueryString != "" {
+		frame.Meta = &data.FrameMeta{ExecutedQueryString: b.options.ExecutedQueryString}
+	}
+	if b.rows == 0 {
+		return frame, nil
+	}
+	for _, c := range b.columns {
+		if !c.seen {
+			continue
+		}
+		switch {
+		case c.deferred:
+			if err := b.convertColumn(c); err != nil {
+				return frame, err
+			}
+		case c.converter == nil && c.field != nil && !b.options.InferTypes:
+			// all the values are strings, so the field type is string whatever their kinds are
+			frame.Fields = append(frame.Fields, c.field)
+			if c.kinds.mixed() {
+				addNotice(frame, inferenceNotice(c.name, c.kinds))
+			}
+			continue
+		case c.converter == nil:
+			values := c.values
+			if c.field != nil {
+				values = fieldValues(c.field)
+			}
+			if err := appendUntypedField(frame, c.name, values, inferColumnType(values, b.options), c.column, b.options); err != nil {
+				return frame, err
+			}
+			continue
+		}
+		frame.Fields = append(frame.Fields, c.field)
+		addConvertedSchemaField(frame, c.name, c.converter, b.options)
+		if err := c.failures.report(frame, b.options); err != nil {
+			return frame, err
+		}
+	}
+	if len(frame.Fields) == 0 {
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableString, b.rows)
+		field.Name = b.name
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame, nil
+}
+
+// fieldValues returns the values of the string field as strings, or nil for the null values
+func fieldValues(field *data.Field) []interface{} {
+	values := make([]interface{}, field.Len())
+	for i := range values {
+		if s, ok := field.At(i).(*string); ok && s != nil {
+			values[i] = *s
+		}
+	}
+	return values
+}
@@ -0,0 +1,65 @@
+package gframer
+
+// ColumnarMode controls whether objects holding column arrays such as `{"a":[1,2],"b":[3,4]}` are framed as one row per array index
+type ColumnarMode string
+
+const (
+	ColumnarModeNone  ColumnarMode = ""      // objects are framed as a single row. This is the default
+	ColumnarModeAuto  ColumnarMode = "auto"  // objects where all the values are arrays of the same length are framed as columns
+	ColumnarModeForce ColumnarMode = "force" // objects are always framed as columns. Shorter arrays are padded with nulls and non array values are repeated on every row
+)
+
+// columnsToRows converts the column arrays of the input object into rows. Input that is not columnar is returned as is
+func columnsToRows(input interface{}, mode ColumnarMode) interface{} {
+	keys, values, ok := asObject(input)
+	if !ok || len(keys) == 0 || !isColumnar(keys, values, mode) {
+		return input
+	}
+	length, hasArray := 0, false
+	for _, key := range keys {
+		if items, ok := values[key].([]interface{}); ok {
+			hasArray = true
+			if len(items) > length {
+				length = len(items)
+			}
+		}
+	}
+	if !hasArray {
+		length = 1
+	}
+	rows := make([]interface{}, length)
+	for idx := range rows {
+		row := NewOrderedMap()
+		for _, key := range keys {
+			items, ok := values[key].([]interface{})
+			switch {
+			case !ok:
+				row.Set(key, values[key])
+			case idx < len(items):
+				row.Set(key, items[idx])
+			default:
+				row.Set(key, nil)
+			}
+		}
+		rows[idx] = row
+	}
+	return rows
+}
+
+func isColumnar(keys []string, values map[string]interface{}, mode ColumnarMode) bool {
+	switch mode {
+	case ColumnarModeForce:
+		return true
+	case ColumnarModeAuto:
+		length := -1
+		for _, key := range keys {
+			items, ok := values[key].([]interface{})
+			if !ok || (length >= 0 && len(items) != length) {
+				return false
+			}
+			length = len(items)
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,409 @@
+package gframer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
+)
+
+// Converter converts the values of the columns whose ColumnSelector.Type matches the name of the converter.
+// Convert is called only for non null values. Returning nil without error sets the value to null, returning an error reports the value as conversion failure.
+// Converters should accept the values they produce, as the values can be converted more than once (for example by jsonFramer and then by gframer)
+type Converter interface {
+	Name() string
+	FieldType() data.FieldType
+	Convert(value interface{}, column ColumnSelector) (interface{}, error)
+}
+
+// ColumnConverter is implemented by the converters which need all the values of the column, for example to detect the unit of the values.
+// ForColumn returns the converter used for the values of the given column
+type ColumnConverter interface {
+	Converter
+	ForColumn(values []interface{}, column ColumnSelector) Converter
+}
+
+// FieldConfigConverter is implemented by the converters which set the config of the field, such as the unit
+type FieldConfigConverter interface {
+	Converter
+	FieldConfig() *data.FieldConfig
+}
+
+// ConvertFunc converts a single value of the column
+type ConvertFunc func(value interface{}, column ColumnSelector) (interface{}, error)
+
+type converter struct {
+	name      string
+	fieldType data.FieldType
+	convert   ConvertFunc
+}
+
+func (c converter) Name() string              { return c.name }
+func (c converter) FieldType() data.FieldType { return c.fieldType }
+func (c converter) Convert(value interface{}, column ColumnSelector) (interface{}, error) {
+	return c.convert(value, column)
+}
+
+// NewConverter creates a converter from the given function
+func NewConverter(name string, fieldType data.FieldType, convert ConvertFunc) Converter {
+	return converter{name: name, fieldType: fieldType, convert: convert}
+}
+
+// errUnsupportedValue is returned by the converters when the go type of the value can't be converted
+var errUnsupportedValue = errors.New("unsupported value")
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[string]Converter{}
+)
+
+// RegisterConverter adds the converter to the registry. Converter registered with the name of an existing converter, including the built-in ones, replaces it
+func RegisterConverter(c Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[c.Name()] = c
+}
+
+// GetConverter returns the converter registered for the column type
+func GetConverter(name string) (Converter, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	c, ok := converters[name]
+	return c, ok
+}
+
+func init() {
+	RegisterConverter(NewConverter("string", data.FieldTypeNullableString, convertToString))
+	RegisterConverter(NewConverter("number", data.FieldTypeNullableFloat64, convertToNumber))
+	RegisterConverter(NewConverter("boolean", data.FieldTypeNullableBool, convertToBoolean))
+	RegisterConverter(timestampConverter{})
+	RegisterConverter(NewConverter("timestamp_epoch", data.FieldTypeNullableTime, epochConverter(time.Millisecond)))
+	RegisterConverter(NewConverter("timestamp_epoch_s", data.FieldTypeNullableTime, epochConverter(time.Second)))
+	RegisterConverter(NewConverter("timestamp_epoch_us", data.FieldTypeNullableTime, epochConverter(time.Microsecond)))
+	RegisterConverter(NewConverter("timestamp_epoch_ns", data.FieldTypeNullableTime, epochConverter(time.Nanosecond)))
+	RegisterConverter(epochAutoConverter{})
+	RegisterConverter(durationConverter{name: "duration", unit: time.Millisecond, unitName: "ms"})
+	RegisterConverter(durationConverter{name: "duration_ms", unit: time.Millisecond, unitName: "ms"})
+	RegisterConverter(durationConverter{name: "duration_s", unit: time.Second, unitName: "s"})
+	RegisterConverter(NewConverter("int64", data.FieldTypeNullableInt64, convertToInt64))
+	RegisterConverter(NewConverter("uint64", data.FieldTypeNullableUint64, convertToUint64))
+	RegisterConverter(NewConverter("json", data.FieldTypeNullableJSON, convertToJSON))
+}
+
+// newConvertedField creates the field by converting all the values of the column with the converter
+func newConvertedField(name string, values []interface{}, column ColumnSelector, c Converter) (*data.Field, *conversionFailures) {
+	field := data.NewFieldFromFieldType(c.FieldType(), len(values))
+	field.Name = name
+	if cc, ok := c.(ColumnConverter); ok {
+		c = cc.ForColumn(values, column)
+	}
+	if fc, ok := c.(FieldConfigConverter); ok {
+		field.Config = fc.FieldConfig()
+	}
+	failures := newConversionFailures(name, c.Name())
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		out, err := c.Convert(v, column)
+		if err != nil {
+			failures.add(i, v, err)
+			continue
+		}
+		field.Set(i, ToPointer(out))
+	}
+	return field, failures
+}
+
+func convertToString(value interface{}, column ColumnSelector) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number, bool:
+		return fmt.Sprintf("%v", v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	return nil, errUnsupportedValue
+}
+
+func convertToNumber(value interface{}, column ColumnSelector) (interface{}, error) {
+	if v, ok := value.(string); ok {
+		return framerUtils.ParseNumber(v, column.numberFormat())
+	}
+	if v, ok := value.(json.Number); ok {
+		return v.Float64()
+	}
+	if f, ok := toFloat64(value); ok {
+		return f, nil
+	}
+	return nil, errUnsupportedValue
+}
+
+func (c ColumnSelector) numberFormat() framerUtils.NumberFormat {
+	return framerUtils.NumberFormat{
+		DecimalSeparator:   c.DecimalSeparator,
+		ThousandsSeparator: c.ThousandsSeparator,
+		StripCurrency:      c.StripCurrency,
+		StripPercent:       c.StripPercent,
+		AccountingNegative: c.AccountingNegative,
+	}
+}
+
+var (
+	defaultTrueValues  = []string{"true", "yes", "y", "on", "t", "1"}
+	defaultFalseValues = []string{"false", "no", "n", "off", "f", "0"}
+)
+
+// convertToBoolean converts the values found in the true / false vocabularies of the column. Other numeric values are true when non zero
+func convertToBoolean(value interface{}, column ColumnSelector) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		trueValues, falseValues := column.TrueValues, column.FalseValues
+		if len(trueValues) == 0 {
+			trueValues = defaultTrueValues
+		}
+		if len(falseValues) == 0 {
+			falseValues = defaultFalseValues
+		}
+		v = strings.TrimSpace(v)
+		if containsFold(trueValues, v) {
+			return true, nil
+		}
+		if containsFold(falseValues, v) {
+			return false, nil
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f != 0, nil
+		}
+		return nil, fmt.Errorf("unknown boolean value %q", v)
+	}
+	if f, ok := toFloat64(value); ok {
+		return f != 0, nil
+	}
+	return nil, errUnsupportedValue
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// timestampConverter converts the time strings and the numeric timestamps. Numeric values use the keywords of the TimeFormat such as yyyymmdd, excel and julian, or the go layout.
+// The converter of a column reuses its time parser, so that the layout found for the first value is tried first for the rest of the values
+type timestampConverter struct {
+	parser *framerUtils.TimeParser
+	loc    *time.Location
+}
+
+func (timestampConverter) Name() string              { return "timestamp" }
+func (timestampConverter) FieldType() data.FieldType { return data.FieldTypeNullableTime }
+
+func (c timestampConverter) ForColumn(values []interface{}, column ColumnSelector) Converter {
+	loc, err := framerUtils.LoadLocation(column.TimeZone)
+	if err != nil {
+		return c
+	}
+	return timestampConverter{parser: framerUtils.NewTimeParser(column.TimeFormat, loc, column.DateOrder), loc: loc}
+}
+
+func (c timestampConverter) Convert(value interface{}, column ColumnSelector) (interface{}, error) {
+	if v, ok := value.(time.Time); ok {
+		return v, nil
+	}
+	parser, loc := c.parser, c.loc
+	if parser == nil {
+		var err error
+		if loc, err = framerUtils.LoadLocation(column.TimeZone); err != nil {
+			return nil, err
+		}
+		parser = framerUtils.NewTimeParser(column.TimeFormat, loc, column.DateOrder)
+	}
+	if v, ok := value.(string); ok {
+		f, isNumeric := numericValue(v)
+		if isNumeric && framerUtils.IsNumericTimeFormat(column.TimeFormat) {
+			return framerUtils.GetTimeFromNumber(f, column.TimeFormat, loc)
+		}
+		if t := parser.Parse(v); t != nil {
+			return *t, nil
+		}
+		if isNumeric {
+			return framerUtils.GetTimeFromNumber(f, column.TimeFormat, loc)
+		}
+		return nil, fmt.Errorf("unable to parse %q as time", v)
+	}
+	if f, ok := toFloat64(value); ok {
+		return framerUtils.GetTimeFromNumber(f, column.TimeFormat, loc)
+	}
+	return nil, errUnsupportedValue
+}
+
+// applyColumnDefaults validates the time zones, date orders and time formats of the options and sets the defaults of the frame to the columns without one
+func applyColumnDefaults(options FramerOptions) (FramerOptions, error) {
+	if _, err := framerUtils.LoadLocation(options.TimeZone); err != nil {
+		return options, fmt.Errorf("invalid time zone %q. %w", options.TimeZone, err)
+	}
+	if !options.DateOrder.IsValid() {
+		return options, fmt.Errorf("invalid date order %q", options.DateOrder)
+	}
+	columns := make([]ColumnSelector, len(options.Columns))
+	for idx, c := range options.Columns {
+		if c.TimeZone == "" {
+			c.TimeZone = options.TimeZone
+		}
+		if c.DateOrder == "" {
+			c.DateOrder = options.DateOrder
+		}
+		if _, err := framerUtils.LoadLocation(c.TimeZone); err != nil {
+			return options, fmt.Errorf("invalid time zone %q of the column %q. %w", c.TimeZone, c.Selector, err)
+		}
+		if !c.DateOrder.IsValid() {
+			return options, fmt.Errorf("invalid date order %q of the column %q", c.DateOrder, c.Selector)
+		}
+		if err := framerUtils.ValidateTimeFormat(c.TimeFormat); err != nil {
+			return options, fmt.Errorf("invalid time format of the column %q. %w", c.Selector, err)
+		}
+		columns[idx] = c
+	}
+	if len(options.Columns) > 0 {
+		options.Columns = columns
+	}
+	return options, nil
+}
+
+// epochConverter returns the converter of the epoch values given in the unit. Fractional values are supported
+func epochConverter(unit time.Duration) ConvertFunc {
+	return func(value interface{}, column ColumnSelector) (interface{}, error) {
+		if v, ok := value.(time.Time); ok {
+			return v, nil
+		}
+		perSecond := int64(time.Second / unit)
+		if item, ok := toInt64(value); ok {
+			// integers are converted without float64 so that the nanosecond epochs retain their precision
+			return time.Unix(item/perSecond, (item%perSecond)*int64(unit)), nil
+		}
+		f, ok := numericValue(value)
+		if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errUnsupportedValue
+		}
+		whole := math.Floor(f)
+		if math.Abs(whole) >= math.MaxInt64 {
+			return nil, errUnsupportedValue
+		}
+		item := int64(whole)
+		return time.Unix(item/perSecond, (item%perSecond)*int64(unit)+int64(math.Round((f-whole)*float64(unit)))), nil
+	}
+}
+
+// epochAutoConverter detects the unit of the epoch values (seconds, milliseconds, microseconds or nanoseconds) from the magnitude of the largest value of the column
+type epochAutoConverter struct{}
+
+func (epochAutoConverter) Name() string              { return "timestamp_epoch_auto" }
+func (epochAutoConverter) FieldType() data.FieldType { return data.FieldTypeNullableTime }
+
+// Convert detects the unit from the value itself. Framers use ForColumn instead so that all the values of the column use the same unit
+func (c epochAutoConverter) Convert(value interface{}, column ColumnSelector) (interface{}, error) {
+	return epochConverter(detectEpochUnit([]interface{}{value}))(value, column)
+}
+
+func (c epochAutoConverter) ForColumn(values []interface{}, column ColumnSelector) Converter {
+	return NewConverter(c.Name(), c.FieldType(), epochConverter(detectEpochUnit(values)))
+}
+
+// detectEpochUnit returns the epoch unit based on the largest value. Seconds up to 1e11 (year 5138), milliseconds up to 1e14, microseconds up to 1e17 and nanoseconds beyond that
+func detectEpochUnit(values []interface{}) time.Duration {
+	max := 0.0
+	for _, v := range values {
+		if f, ok := numericValue(v); ok && math.Abs(f) > max {
+			max = math.Abs(f)
+		}
+	}
+	switch {
+	case max < 1e11:
+		return time.Second
+	case max < 1e14:
+		return time.Millisecond
+	case max < 1e17:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
+
+// numericValue returns the number or the numeric string as float64
+func numericValue(value interface{}) (float64, bool) {
+	if v, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return toFloat64(value)
+}
+
+// durationConverter converts the go, iso-8601 and clock durations to number of the unit. Numbers are treated as the number of the unit.
+// duration type is the same as duration_ms
+type durationConverter struct {
+	name     string
+	unit     time.Duration
+	unitName string
+}
+
+func (c durationConverter) Name() string              { return c.name }
+func (c durationConverter) FieldType() data.FieldType { return data.FieldTypeNullableFloat64 }
+func (c durationConverter) FieldConfig() *data.FieldConfig {
+	return &data.FieldConfig{Unit: c.unitName}
+}
+
+func (c durationConverter) Convert(value interface{}, column ColumnSelector) (interface{}, error) {
+	if d, ok := value.(time.Duration); ok {
+		return float64(d) / float64(c.unit), nil
+	}
+	if f, ok := numericValue(value); ok {
+		return f, nil
+	}
+	if v, ok := value.(string); ok {
+		d, err := framerUtils.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		return float64(d) / float64(c.unit), nil
+	}
+	return nil, errUnsupportedValue
+}
+
+func convertToInt64(value interface{}, column ColumnSelector) (interface{}, error) {
+	if item, ok := toInt64(value); ok {
+		return item, nil
+	}
+	return nil, errUnsupportedValue
+}
+
+func convertToUint64(value interface{}, column ColumnSelector) (interface{}, error) {
+	if item, ok := toUint64(value); ok {
+		return item, nil
+	}
+	return nil, errUnsupportedValue
+}
+
+func convertToJSON(value interface{}, column ColumnSelector) (interface{}, error) {
+	if v, ok := value.(json.RawMessage); ok {
+		return v, nil
+	}
+	o, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(o), nil
+}
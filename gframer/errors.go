@@ -0,0 +1,74 @@
+package gframer
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrEmptyInput is returned when the input of the framer is empty
+	ErrEmptyInput = errors.New("empty input received")
+	// ErrUnsupportedInput is returned when the input can't be represented as frame
+	ErrUnsupportedInput = errors.New("unable to construct frame")
+)
+
+// ErrInvalidInput is returned when the input is not valid json / csv etc. Offset and Line are the position of the error in the input, when known
+type ErrInvalidInput struct {
+	Format string
+	Offset int64
+	Line   int
+	Err    error
+}
+
+func (e *ErrInvalidInput) Error() string {
+	msg := fmt.Sprintf("invalid %s input received", e.Format)
+	if e.Line > 0 {
+		msg = fmt.Sprintf("%s at line %d", msg, e.Line)
+	}
+	if e.Offset > 0 {
+		msg = fmt.Sprintf("%s (offset %d)", msg, e.Offset)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s. %s", msg, e.Err.Error())
+	}
+	return msg
+}
+
+func (e *ErrInvalidInput) Unwrap() error {
+	return e.Err
+}
+
+// ErrRootNotFound is returned when the root selector doesn't match anything in the input
+type ErrRootNotFound struct {
+	Selector string
+	Err      error
+}
+
+func (e *ErrRootNotFound) Error() string {
+	return "root object doesn't exist in the response. Root selector:" + e.Selector
+}
+
+func (e *ErrRootNotFound) Unwrap() error {
+	return e.Err
+}
+
+// ErrConversion is returned when a value can't be converted to the type of the column
+type ErrConversion struct {
+	Column string
+	Row    int
+	Value  interface{}
+	Type   string
+	Err    error
+}
+
+func (e *ErrConversion) Error() string {
+	msg := fmt.Sprintf("unable to convert value %v of field %q at row %d to %s", e.Value, e.Column, e.Row, e.Type)
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s. %s", msg, e.Err.Error())
+	}
+	return msg
+}
+
+func (e *ErrConversion) Unwrap() error {
+	return e.Err
+}
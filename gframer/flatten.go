@@ -0,0 +1,108 @@
+package gframer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FlattenOptions controls how nested objects are expanded into individual fields
+type FlattenOptions struct {
+	Enabled   bool
+	MaxDepth  int    // number of nested levels to expand. 0 means no limit. Objects beyond the depth are kept as json
+	Separator string // separator used to join the keys. Defaults to `.`
+	Arrays    bool   // expand arrays into indexed fields such as `tags.0`. By default arrays are kept as json
+}
+
+func (o FlattenOptions) separator() string {
+	if o.Separator == "" {
+		return "."
+	}
+	return o.Separator
+}
+
+// flatten expands the nested objects of the input object or the input rows
+func flatten(input interface{}, options FramerOptions) interface{} {
+	if rows, ok := input.([]interface{}); ok {
+		out := make([]interface{}, len(rows))
+		for idx, row := range rows {
+			out[idx] = flattenObject(row, options)
+		}
+		return out
+	}
+	return flattenObject(input, options)
+}
+
+func flattenObject(input interface{}, options FramerOptions) interface{} {
+	keys, values, ok := asObject(input)
+	if !ok {
+		return input
+	}
+	aliases := map[string]string{}
+	for _, c := range options.Columns {
+		if c.Alias != "" && c.Selector != "" {
+			aliases[c.Selector] = c.Alias
+		}
+	}
+	out := NewOrderedMap()
+	for _, key := range keys {
+		flattenValue(out, key, values[key], 0, options.Flatten, aliases)
+	}
+	return out
+}
+
+func flattenValue(out *OrderedMap, name string, value interface{}, depth int, options FlattenOptions, aliases map[string]string) {
+	canExpand := options.MaxDepth <= 0 || depth < options.MaxDepth
+	if canExpand {
+		if keys, values, ok := asObject(value); ok && len(keys) > 0 {
+			for _, key := range keys {
+				flattenValue(out, name+options.separator()+key, values[key], depth+1, options, aliases)
+			}
+			return
+		}
+		if items, ok := value.([]interface{}); ok && options.Arrays && len(items) > 0 {
+			for idx, item := range items {
+				flattenValue(out, name+options.separator()+strconv.Itoa(idx), item, depth+1, options, aliases)
+			}
+			return
+		}
+	}
+	if alias, ok := aliases[name]; ok && depth > 0 {
+		name = alias
+	}
+	out.Set(name, value)
+}
+
+// matchesColumn checks whether the field name is addressed by the column selector.
+// When flattening is enabled, a selector also addresses all the fields expanded from it
+func matchesColumn(c ColumnSelector, name string, options FramerOptions) bool {
+	if c.Alias == name || (c.Alias == "" && c.Selector == name) {
+		return true
+	}
+	if !options.Flatten.Enabled {
+		return false
+	}
+	prefix := c.Alias
+	if prefix == "" {
+		prefix = c.Selector
+	}
+	return prefix != "" && strings.HasPrefix(name, prefix+options.Flatten.separator())
+}
+
+// columnIndex returns the index of the column selector of the field name, or -1 when no selector addresses it.
+// A field gets a single column: the selector of the field itself wins over the selectors of its parents, then the nearest parent wins
+func columnIndex(name string, options FramerOptions) int {
+	best, bestLength := -1, -1
+	for idx, c := range options.Columns {
+		if c.Alias == name || (c.Alias == "" && c.Selector == name) {
+			return idx
+		}
+		prefix := c.Alias
+		if prefix == "" {
+			prefix = c.Selector
+		}
+		if matchesColumn(c, name, options) && len(prefix) > bestLength {
+			best, bestLength = idx, len(prefix)
+		}
+	}
+	return best
+}
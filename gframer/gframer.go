@@ -2,10 +2,7 @@ package gframer
 
 import (
 	"encoding/json"
-	"errors"
-	"fmt"
 	"sort"
-	"strconv"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -13,71 +10,85 @@ import (
 )
 
 type ColumnSelector struct {
-	Selector   string
-	Alias      string
-	Type       string
-	TimeFormat string
+	Selector    string
+	Alias       string
+	Type        string
+	TimeFormat  string
+	TrueValues  []string              // values treated as true by the boolean type, case insensitive. Defaults to true, yes, y, on, t and 1
+	FalseValues []string              // values treated as false by the boolean type, case insensitive. Defaults to false, no, n, off, f and 0
+	TimeZone    string                // IANA time zone name such as Europe/London, used for the timestamps without zone information. Defaults to FramerOptions.TimeZone
+	DateOrder   framerUtils.DateOrder // MDY, DMY or YMD. order of the ambiguous dates such as 01/02/2006. Defaults to FramerOptions.DateOrder
+	// options of the number type for the numbers given as strings
+	DecimalSeparator   string // `,` for 1.234,56. Defaults to `.`
+	ThousandsSeparator string // `,` for 1,234.56
+	StripCurrency      bool   // remove the currency symbols such as $, € and £
+	StripPercent       bool   // remove the percent sign. 45% becomes 45
+	AccountingNegative bool   // numbers in parentheses such as (300) are negative
 }
 
 type FramerOptions struct {
 	FrameName           string
 	ExecutedQueryString string
 	Columns             []ColumnSelector
+	FieldOrder          FieldOrder
+	Flatten             FlattenOptions
+	InferIntegers       bool // emit int64/uint64 fields for the columns where every value is an integer
+	JSONFields          bool // emit objects and arrays as json fields instead of json strings
+	Columnar            ColumnarMode
+	Strict              bool                  // return ErrConversion when a value can't be converted to the column type, instead of setting null and reporting a notice
+	Header              []string              // field names of the rows given as arrays such as [[1,"a"],[2,"b"]]
+	FirstRowHeader      bool                  // use the first row as field names when the rows are given as arrays
+	TimeZone            string                // default IANA time zone name of the columns. Timestamps without zone information are treated as UTC when not set
+	DateOrder           framerUtils.DateOrder // default order of the ambiguous dates of the columns. MDY when not set
+	InferTypes          bool                  // convert the columns of strings without type to number, boolean or timestamp when all their values are of that type. The types are reported in the frame meta as FrameMetaCustom
 }
 
 func noOperation(x interface{}) {}
 
 func ToDataFrame(input interface{}, options FramerOptions) (frame *data.Frame, err error) {
+	input, options = normalizeInput(input, options)
+	if options.Columnar != ColumnarModeNone {
+		input = columnsToRows(input, options.Columnar)
+	}
+	input, options = arraysToRows(input, options)
+	if options, err = applyColumnDefaults(options); err != nil {
+		return frame, err
+	}
+	if options.Flatten.Enabled {
+		input = flatten(input, options)
+	}
 	switch x := input.(type) {
-	case nil, string, float64, float32, int64, int32, int16, int, bool:
-		return structToFrame(options.FrameName, map[string]interface{}{options.FrameName: input}, options.ExecutedQueryString)
+	case nil, string, float64, float32, int64, int32, int16, int, uint64, bool, json.Number, time.Time, time.Duration:
+		return structToFrame(options.FrameName, map[string]interface{}{options.FrameName: input}, options)
 	case []interface{}:
 		return sliceToFrame(options.FrameName, input.([]interface{}), options)
 	default:
 		noOperation(x)
-		return structToFrame(options.FrameName, input, options.ExecutedQueryString)
+		return structToFrame(options.FrameName, input, options)
 	}
 }
 
-func structToFrame(name string, input interface{}, executedQueryString string) (frame *data.Frame, err error) {
+func structToFrame(name string, input interface{}, options FramerOptions) (frame *data.Frame, err error) {
 	frame = data.NewFrame(name)
-	if executedQueryString != "" {
+	if options.ExecutedQueryString != "" {
 		frame.Meta = &data.FrameMeta{
-			ExecutedQueryString: executedQueryString,
+			ExecutedQueryString: options.ExecutedQueryString,
 		}
 	}
-	if in, ok := input.(map[string]interface{}); ok {
+	if keys, in, ok := asObject(input); ok {
 		fields := map[string]*data.Field{}
 		for key, value := range in {
-			switch x := value.(type) {
-			case nil, string, float64, float32, int64, int32, int16, int, bool:
-				noOperation(x)
-				a, b := getFieldTypeAndValue(value)
-				field := data.NewFieldFromFieldType(a, 1)
-				field.Name = key
-				field.Set(0, ToPointer(b))
-				fields[key] = field
-			default:
-				fieldType, b := getFieldTypeAndValue(value)
-				if fieldType == data.FieldTypeJSON {
-					fieldType = data.FieldTypeNullableString
-				}
-				field := data.NewFieldFromFieldType(fieldType, 1)
-				field.Name = key
-				if o, err := json.Marshal(b); err == nil {
-					field.Set(0, ToPointer(string(o)))
-					fields[key] = field
-				}
-			}
+			values := []interface{}{value}
+			fields[key] = newInferredField(key, values, inferColumnType(values, options), options)
 		}
-		for _, key := range sortedKeys(in) {
+		for _, key := range orderFieldNames(keys, options) {
 			if f, ok := fields[key]; ok && f != nil {
 				frame.Fields = append(frame.Fields, f)
 			}
 		}
 		return frame, err
 	}
-	err = errors.New("unable to construct frame")
+	err = ErrUnsupportedInput
 	return frame, err
 }
 
@@ -94,173 +105,58 @@ func sliceToFrame(name string, input []interface{}, options FramerOptions) (fram
 	for _, item := range input {
 		if item != nil {
 			switch item.(type) {
-			case string, float64, float32, int64, int32, int16, int, bool:
-				a, _ := getFieldTypeAndValue(item)
-				field := data.NewFieldFromFieldType(a, len(input))
-				field.Name = name
-				for idx, i := range input {
-					field.Set(idx, ToPointer(i))
-				}
-				frame.Fields = append(frame.Fields, field)
-			case []interface{}:
-				field := data.NewFieldFromFieldType(data.FieldTypeNullableString, len(input))
-				field.Name = name
-				for idx, i := range input {
-					if o, err := json.Marshal(i); err == nil {
-						field.Set(idx, ToPointer(string(o)))
-					}
-				}
-				frame.Fields = append(frame.Fields, field)
+			case string, float64, float32, int64, int32, int16, int, uint64, bool, json.Number, time.Time, time.Duration, []interface{}:
+				appendInferredField(frame, name, input, inferColumnType(input, options), options)
 			default:
 				results := map[string]map[int]interface{}{}
+				keys := []string{}
 				for idx, id := range input {
-					if o, ok := id.(map[string]interface{}); ok {
-						for k, v := range o {
+					if rowKeys, o, ok := asObject(id); ok {
+						for _, k := range rowKeys {
 							if results[k] == nil {
 								results[k] = map[int]interface{}{}
+								keys = append(keys, k)
 							}
-							results[k][idx] = v
+							results[k][idx] = o[k]
 						}
 					}
 				}
-				for _, k := range sortedKeys(results) {
+				for _, k := range orderFieldNames(keys, options) {
 					if results[k] != nil {
 						o := []interface{}{}
 						for i := 0; i < len(input); i++ {
 							o = append(o, results[k][i])
 						}
-						fieldType := getFieldTypeFromSlice(o)
+						ct := inferColumnType(o, options)
+						fieldType := ct.fieldType
 						if fieldType == data.FieldTypeJSON {
-							field := data.NewFieldFromFieldType(data.FieldTypeNullableString, len(input))
-							field.Name = k
-							for i := 0; i < len(input); i++ {
-								if o, err := json.Marshal(o[i]); err == nil {
-									field.Set(i, ToPointer(string(o)))
-								}
+							if err := appendUntypedField(frame, k, o, ct, ColumnSelector{}, options); err != nil {
+								return frame, err
 							}
-							frame.Fields = append(frame.Fields, field)
 						}
 						if fieldType != data.FieldTypeJSON {
 							if len(options.Columns) > 0 {
-								for _, c := range options.Columns {
-									if c.Alias == k || (c.Alias == "" && c.Selector == k) {
-										switch c.Type {
-										case "string":
-											field := data.NewFieldFromFieldType(data.FieldTypeNullableString, len(input))
-											field.Name = k
-											for i := 0; i < len(input); i++ {
-												currentValue := o[i]
-												switch cvt := currentValue.(type) {
-												case string:
-													field.Set(i, ToPointer(currentValue.(string)))
-												case float64, float32, int, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-													field.Set(i, ToPointer(fmt.Sprintf("%v", currentValue)))
-												case bool:
-													field.Set(i, ToPointer(fmt.Sprintf("%v", currentValue.(bool))))
-												default:
-													noOperation(cvt)
-													field.Set(i, nil)
-												}
-											}
-											frame.Fields = append(frame.Fields, field)
-										case "number":
-											field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(input))
-											field.Name = k
-											for i := 0; i < len(input); i++ {
-												currentValue := o[i]
-												switch cvt := currentValue.(type) {
-												case string:
-													if item, err := strconv.ParseFloat(currentValue.(string), 64); err == nil {
-														field.Set(i, ToPointer(item))
-													}
-												case float64:
-													field.Set(i, ToPointer(currentValue.(float64)))
-												default:
-													noOperation(cvt)
-													field.Set(i, nil)
-												}
-											}
-											frame.Fields = append(frame.Fields, field)
-										case "timestamp":
-											field := data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(input))
-											field.Name = k
-											for i := 0; i < len(input); i++ {
-												currentValue := o[i]
-												switch a := currentValue.(type) {
-												case float64:
-													if v := fmt.Sprintf("%.0f", currentValue); v != "" {
-														format := "2006"
-														if c.TimeFormat != "" {
-															format = c.TimeFormat
-														}
-														if t, err := time.Parse(format, v); err == nil {
-															field.Set(i, ToPointer(t))
-														}
-													}
-												case string:
-													if currentValue.(string) != "" {
-														field.Set(i, framerUtils.GetTimeFromString(currentValue.(string), c.TimeFormat))
-													}
-												default:
-													noOperation(a)
-													field.Set(i, nil)
-												}
-											}
-											frame.Fields = append(frame.Fields, field)
-										case "timestamp_epoch":
-											field := data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(input))
-											field.Name = k
-											for i := 0; i < len(input); i++ {
-												currentValue := o[i]
-												switch cvt := currentValue.(type) {
-												case string:
-													if item, err := strconv.ParseInt(currentValue.(string), 10, 64); err == nil && currentValue.(string) != "" {
-														field.Set(i, ToPointer(time.UnixMilli(item)))
-													}
-												case float64:
-													field.Set(i, ToPointer(time.UnixMilli(int64(currentValue.(float64)))))
-												default:
-													noOperation(cvt)
-													field.Set(i, nil)
-												}
-											}
-											frame.Fields = append(frame.Fields, field)
-										case "timestamp_epoch_s":
-											field := data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(input))
-											field.Name = k
-											for i := 0; i < len(input); i++ {
-												currentValue := o[i]
-												switch cvt := currentValue.(type) {
-												case string:
-													if item, err := strconv.ParseInt(currentValue.(string), 10, 64); err == nil && currentValue.(string) != "" {
-														field.Set(i, ToPointer(time.Unix(item, 0)))
-													}
-												case float64:
-													field.Set(i, ToPointer(time.Unix(int64(currentValue.(float64)), 0)))
-												default:
-													noOperation(cvt)
-													field.Set(i, nil)
-												}
-											}
-											frame.Fields = append(frame.Fields, field)
-										default:
-											field := data.NewFieldFromFieldType(fieldType, len(input))
-											field.Name = k
-											for i := 0; i < len(input); i++ {
-												field.Set(i, ToPointer(o[i]))
-											}
-											frame.Fields = append(frame.Fields, field)
+								if idx := columnIndex(k, options); idx >= 0 {
+									c := options.Columns[idx]
+									converter, ok := GetConverter(c.Type)
+									if !ok {
+										if err := appendUntypedField(frame, k, o, ct, c, options); err != nil {
+											return frame, err
+										}
+									} else {
+										field, failures := newConvertedField(k, o, c, converter)
+										frame.Fields = append(frame.Fields, field)
+										addConvertedSchemaField(frame, k, converter, options)
+										if err := failures.report(frame, options); err != nil {
+											return frame, err
 										}
 									}
 								}
 							}
 							if len(options.Columns) < 1 {
-								field := data.NewFieldFromFieldType(fieldType, len(input))
-								field.Name = k
-								for i := 0; i < len(input); i++ {
-									field.Set(i, ToPointer(o[i]))
+								if err := appendUntypedField(frame, k, o, ct, ColumnSelector{}, options); err != nil {
+									return frame, err
 								}
-								frame.Fields = append(frame.Fields, field)
 							}
 						}
 					}
@@ -277,44 +173,6 @@ func sliceToFrame(name string, input []interface{}, options FramerOptions) (fram
 	return frame, nil
 }
 
-func getFieldTypeAndValue(value interface{}) (t data.FieldType, out interface{}) {
-	switch x := value.(type) {
-	case nil:
-		return data.FieldTypeNullableString, value
-	case string:
-		return data.FieldTypeNullableString, value
-	case float64:
-		return data.FieldTypeNullableFloat64, value
-	case float32:
-		return data.FieldTypeNullableFloat64, float64(value.(float32))
-	case int64:
-		return data.FieldTypeNullableFloat64, float64(value.(int64))
-	case int32:
-		return data.FieldTypeNullableFloat64, float64(value.(int32))
-	case int16:
-		return data.FieldTypeNullableFloat64, float64(value.(int16))
-	case int:
-		return data.FieldTypeNullableFloat64, float64(value.(int))
-	case bool:
-		return data.FieldTypeNullableBool, value
-	case interface{}:
-		return data.FieldTypeJSON, value
-	default:
-		noOperation(x)
-		return data.FieldTypeNullableString, value
-	}
-}
-
-func getFieldTypeFromSlice(value []interface{}) (t data.FieldType) {
-	for _, item := range value {
-		if item != nil {
-			a, _ := getFieldTypeAndValue(item)
-			return a
-		}
-	}
-	return data.FieldTypeNullableString
-}
-
 func sortedKeys(in interface{}) []string {
 	if input, ok := in.(map[string]interface{}); ok {
 		keys := make([]string, len(input))
@@ -396,6 +254,10 @@ func ToPointer(value interface{}) interface{} {
 		return &v
 	case *time.Time:
 		return value
+	case json.RawMessage:
+		return &v
+	case *json.RawMessage:
+		return value
 	default:
 		return nil
 	}
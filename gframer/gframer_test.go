@@ -2,12 +2,18 @@ package gframer_test
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
+	_ "time/tzdata"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
 	"github.com/yesoreyeram/grafana-framer/gframer"
 )
 
@@ -65,6 +71,60 @@ func TestToDataFrame(t *testing.T) {
 		require.NotNil(t, gotFrame)
 		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
 	})
+	t.Run("object-source-order", func(t *testing.T) {
+		input := `{ "name":"foo", "age": 12, "hobbies":["cricket","music"], "isPrimeUser": true, "fullname": { "last": "bar", "first":"foo" } }`
+		options := gframer.FramerOptions{FrameName: t.Name(), FieldOrder: gframer.FieldOrderSource}
+		out, err := gframer.DecodeJSON([]byte(input))
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("object-flatten", func(t *testing.T) {
+		input := `{ "name":"foo", "user": { "age": 12, "address": { "city": "london", "geo": { "lat": 51.5 } } }, "tags": ["a","b"] }`
+		options := gframer.FramerOptions{FrameName: t.Name(), Flatten: gframer.FlattenOptions{Enabled: true, MaxDepth: 2}}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("object-flatten-arrays", func(t *testing.T) {
+		input := `{ "name":"foo", "user": { "age": 12, "address": { "city": "london" } }, "tags": ["a","b"] }`
+		options := gframer.FramerOptions{FrameName: t.Name(), Flatten: gframer.FlattenOptions{Enabled: true, Separator: "_", Arrays: true}}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("object-json-fields", func(t *testing.T) {
+		input := `{ "name":"foo", "hobbies":["cricket","music"], "fullname": { "first": "foo", "last":"bar" } }`
+		options := gframer.FramerOptions{FrameName: t.Name(), JSONFields: true}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("object-json-column", func(t *testing.T) {
+		input := `{ "name":"foo", "hobbies":["cricket","music"], "fullname": { "first": "foo", "last":"bar" } }`
+		options := gframer.FramerOptions{FrameName: t.Name(), Columns: []gframer.ColumnSelector{{Selector: "fullname", Type: "json"}, {Selector: "name", Type: "json"}}}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
 	t.Run("string-array", func(t *testing.T) {
 		input := `["foo","bar"]`
 		options := gframer.FramerOptions{FrameName: t.Name(), ExecutedQueryString: input}
@@ -87,6 +147,17 @@ func TestToDataFrame(t *testing.T) {
 		require.NotNil(t, gotFrame)
 		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
 	})
+	t.Run("mixed-array", func(t *testing.T) {
+		input := `[12,"foo",true,null]`
+		options := gframer.FramerOptions{FrameName: t.Name(), ExecutedQueryString: input}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
 	t.Run("array-inside-array", func(t *testing.T) {
 		input := `[["one","two"],["three"]]`
 		options := gframer.FramerOptions{FrameName: t.Name(), ExecutedQueryString: input}
@@ -98,6 +169,39 @@ func TestToDataFrame(t *testing.T) {
 		require.NotNil(t, gotFrame)
 		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
 	})
+	t.Run("array-rows-first-row-header", func(t *testing.T) {
+		input := `[["time","host","value"],["2022-03-01T10:00:00Z","foo",1],["2022-03-01T10:01:00Z","bar",2.5,"extra"]]`
+		options := gframer.FramerOptions{FrameName: t.Name(), FirstRowHeader: true, FieldOrder: gframer.FieldOrderSource}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("array-rows-header", func(t *testing.T) {
+		input := `[[1645093800,"1.5"],[1645093860,"2"]]`
+		options := gframer.FramerOptions{FrameName: t.Name(), Header: []string{"time", "value"}, Columns: []gframer.ColumnSelector{{Selector: "time", Type: "timestamp_epoch_s"}, {Selector: "value", Type: "number"}}}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("array-rows-index-columns", func(t *testing.T) {
+		input := `[[1645093800,"1.5","foo"],[1645093860,"2","bar"]]`
+		options := gframer.FramerOptions{FrameName: t.Name(), Columns: []gframer.ColumnSelector{{Selector: "0", Alias: "time", Type: "timestamp_epoch_s"}, {Selector: "1", Type: "number"}}}
+		var out interface{}
+		err := json.Unmarshal([]byte(input), &out)
+		require.Nil(t, err)
+		gotFrame, err := gframer.ToDataFrame(out, options)
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "structs/"+strings.ReplaceAll(t.Name(), "TestToDataFrame/", ""), gotFrame, updateGoldenText)
+	})
 	t.Run("all-null-array", func(t *testing.T) {
 		input := `[null,null]`
 		options := gframer.FramerOptions{FrameName: t.Name(), ExecutedQueryString: input}
@@ -111,6 +215,68 @@ func TestToDataFrame(t *testing.T) {
 	})
 }
 
+type testBase struct {
+	ID   int64  `frame:"id"`
+	Host string `json:"host"`
+}
+
+type testRow struct {
+	testBase
+	Time     time.Time     `frame:"time"`
+	Created  string        `frame:"created,type=timestamp,format=2006-01-02"`
+	Value    *float64      `frame:"value"`
+	Elapsed  time.Duration `frame:"elapsed"`
+	Comment  string        `frame:"comment,omitempty"`
+	Internal string        `frame:"-"`
+	Labels   map[string]string
+	OnChange func()
+	Events   chan string
+	hidden   string
+}
+
+func TestToDataFrameReflection(t *testing.T) {
+	updateGoldenText := false
+	ts := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	rows := []testRow{
+		{testBase: testBase{ID: 1, Host: "foo"}, Time: ts, Created: "2022-01-01", Value: toPointer(1.5), Elapsed: 90 * time.Second, Comment: "ok", Labels: map[string]string{"env": "prod"}, OnChange: func() {}, Events: make(chan string), hidden: "x"},
+		{testBase: testBase{ID: 2, Host: "bar"}, Time: ts.Add(time.Minute), Created: "2022-01-02", Elapsed: time.Millisecond, Internal: "y"},
+	}
+	t.Run("struct-slice", func(t *testing.T) {
+		gotFrame, err := gframer.ToDataFrame(rows, gframer.FramerOptions{FrameName: t.Name(), FieldOrder: gframer.FieldOrderSource})
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		for _, name := range []string{"OnChange", "Events"} {
+			_, idx := gotFrame.FieldByName(name)
+			require.Equal(t, -1, idx, name)
+		}
+		experimental.CheckGoldenJSONFrame(t, "testdata", "reflection/"+strings.ReplaceAll(t.Name(), "TestToDataFrameReflection/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("struct-pointer", func(t *testing.T) {
+		gotFrame, err := gframer.ToDataFrame(&rows[0], gframer.FramerOptions{FrameName: t.Name()})
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "reflection/"+strings.ReplaceAll(t.Name(), "TestToDataFrameReflection/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("typed-map-slice", func(t *testing.T) {
+		input := []map[string]string{{"name": "foo", "city": "london"}, {"name": "bar"}}
+		gotFrame, err := gframer.ToDataFrame(input, gframer.FramerOptions{FrameName: t.Name()})
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "reflection/"+strings.ReplaceAll(t.Name(), "TestToDataFrameReflection/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("typed-map", func(t *testing.T) {
+		input := map[string]int{"a": 1, "b": 2}
+		gotFrame, err := gframer.ToDataFrame(input, gframer.FramerOptions{FrameName: t.Name(), InferIntegers: true})
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "reflection/"+strings.ReplaceAll(t.Name(), "TestToDataFrameReflection/", ""), gotFrame, updateGoldenText)
+	})
+}
+
+func toPointer[T any](value T) *T {
+	return &value
+}
+
 func TestToDataFrameSlices(t *testing.T) {
 	updateGoldenText := false
 	files, err := ioutil.ReadDir("./testdata/slices")
@@ -135,3 +301,201 @@ func TestToDataFrameSlices(t *testing.T) {
 		}
 	}
 }
+
+func TestRegisterConverter(t *testing.T) {
+	updateGoldenText := false
+	errNotString := errors.New("not a string")
+	gframer.RegisterConverter(gframer.NewConverter("upper", data.FieldTypeNullableString, func(value interface{}, column gframer.ColumnSelector) (interface{}, error) {
+		if v, ok := value.(string); ok {
+			return strings.ToUpper(v), nil
+		}
+		return nil, errNotString
+	}))
+	converter, ok := gframer.GetConverter("upper")
+	require.True(t, ok)
+	require.Equal(t, data.FieldTypeNullableString, converter.FieldType())
+	input := []interface{}{
+		map[string]interface{}{"name": "foo", "value": 1.5},
+		map[string]interface{}{"name": "bar", "value": 2.5},
+		map[string]interface{}{"name": true, "value": nil},
+	}
+	columns := []gframer.ColumnSelector{{Selector: "name", Type: "upper"}, {Selector: "value", Type: "number"}}
+	t.Run("custom", func(t *testing.T) {
+		gotFrame, err := gframer.ToDataFrame(input, gframer.FramerOptions{FrameName: t.Name(), Columns: columns})
+		require.Nil(t, err)
+		require.NotNil(t, gotFrame)
+		experimental.CheckGoldenJSONFrame(t, "testdata", "converters/"+strings.ReplaceAll(t.Name(), "TestRegisterConverter/", ""), gotFrame, updateGoldenText)
+	})
+	t.Run("custom-strict", func(t *testing.T) {
+		_, err := gframer.ToDataFrame(input, gframer.FramerOptions{FrameName: t.Name(), Columns: columns, Strict: true})
+		var conversionError *gframer.ErrConversion
+		require.ErrorAs(t, err, &conversionError)
+		require.Equal(t, 2, conversionError.Row)
+		require.Equal(t, "upper", conversionError.Type)
+		require.ErrorIs(t, err, errNotString)
+	})
+}
+
+func TestToDataFrameTimeZone(t *testing.T) {
+	input := []interface{}{
+		map[string]interface{}{"local": "2022-03-27 00:30", "day": 20221106.0},
+		map[string]interface{}{"local": "2022-03-27 03:30", "day": 20221107.0},
+		map[string]interface{}{"local": "2022-10-30 02:30", "day": nil},
+		map[string]interface{}{"local": "2022-03-27T00:30:00+02:00", "day": nil},
+		map[string]interface{}{"local": "2022-03-27 01:30", "day": nil},
+		map[string]interface{}{"local": "2022-10-30 01:30", "day": nil},
+	}
+	options := gframer.FramerOptions{TimeZone: "Europe/London", Columns: []gframer.ColumnSelector{
+		{Selector: "local", Type: "timestamp"},
+		{Selector: "day", Type: "timestamp", TimeFormat: "20060102", TimeZone: "America/New_York"},
+	}}
+	frame, err := gframer.ToDataFrame(input, options)
+	require.Nil(t, err)
+	require.Len(t, frame.Fields, 2)
+	day, local := frame.Fields[0], frame.Fields[1]
+	// london switches to BST at 01:00 UTC on 27 March and back to GMT at 01:00 UTC on 30 October
+	require.Equal(t, time.Date(2022, 3, 27, 0, 30, 0, 0, time.UTC), local.At(0).(*time.Time).UTC())
+	require.Equal(t, time.Date(2022, 3, 27, 2, 30, 0, 0, time.UTC), local.At(1).(*time.Time).UTC())
+	require.Equal(t, time.Date(2022, 10, 30, 2, 30, 0, 0, time.UTC), local.At(2).(*time.Time).UTC())
+	// explicit offsets are respected
+	require.Equal(t, time.Date(2022, 3, 26, 22, 30, 0, 0, time.UTC), local.At(3).(*time.Time).UTC())
+	// 01:30 doesn't exist on 27 March as the clocks go from 01:00 GMT to 02:00 BST. It is read as 02:30 BST
+	require.Equal(t, time.Date(2022, 3, 27, 1, 30, 0, 0, time.UTC), local.At(4).(*time.Time).UTC())
+	// 01:30 happens twice on 30 October, at 00:30 UTC in BST and at 01:30 UTC in GMT. The later one is used
+	require.Equal(t, time.Date(2022, 10, 30, 1, 30, 0, 0, time.UTC), local.At(5).(*time.Time).UTC())
+	// new york switches back to EST at 06:00 UTC on 6 November
+	require.Equal(t, time.Date(2022, 11, 6, 4, 0, 0, 0, time.UTC), day.At(0).(*time.Time).UTC())
+	require.Equal(t, time.Date(2022, 11, 7, 5, 0, 0, 0, time.UTC), day.At(1).(*time.Time).UTC())
+	t.Run("invalid time zone", func(t *testing.T) {
+		_, err := gframer.ToDataFrame(input, gframer.FramerOptions{TimeZone: "Mars/Olympus"})
+		require.NotNil(t, err)
+		_, err = gframer.ToDataFrame(input, gframer.FramerOptions{Columns: []gframer.ColumnSelector{{Selector: "local", Type: "timestamp", TimeZone: "Mars/Olympus"}}})
+		require.NotNil(t, err)
+	})
+}
+
+func TestToDataFrameNumericTimestampStrict(t *testing.T) {
+	input := []interface{}{map[string]interface{}{"time": 20220301.0}, map[string]interface{}{"time": 1646092800.0}}
+	options := gframer.FramerOptions{Strict: true, Columns: []gframer.ColumnSelector{{Selector: "time", Type: "timestamp"}}}
+	_, err := gframer.ToDataFrame(input, options)
+	var conversionError *gframer.ErrConversion
+	require.ErrorAs(t, err, &conversionError)
+	require.Equal(t, 1, conversionError.Row)
+	require.ErrorIs(t, err, framerUtils.ErrInvalidNumericTime)
+}
+
+func TestToDataFrameInvalidTimeFormat(t *testing.T) {
+	input := []interface{}{map[string]interface{}{"time": "2022-03-01"}}
+	_, err := gframer.ToDataFrame(input, gframer.FramerOptions{Columns: []gframer.ColumnSelector{{Selector: "time", Type: "timestamp", TimeFormat: "YYYY-MM-DD Do"}}})
+	require.ErrorIs(t, err, framerUtils.ErrUnsupportedTimeFormatToken)
+}
+
+func TestToDataFrameInferTypes(t *testing.T) {
+	input := []interface{}{
+		map[string]interface{}{"a": "1", "b": "true", "c": "03/01/2022", "d": "foo", "e": 1.0},
+		map[string]interface{}{"a": "", "b": "False", "c": "03/02/2022", "d": "2", "e": 2.0},
+	}
+	frame, err := gframer.ToDataFrame(input, gframer.FramerOptions{InferTypes: true, DateOrder: framerUtils.DateOrderDMY})
+	require.Nil(t, err)
+	require.Len(t, frame.Fields, 5)
+	require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[0].Type())
+	require.Nil(t, frame.Fields[0].At(1))
+	require.Equal(t, data.FieldTypeNullableBool, frame.Fields[1].Type())
+	require.Equal(t, time.Date(2022, 2, 3, 0, 0, 0, 0, time.UTC), *frame.Fields[2].At(1).(*time.Time))
+	require.Equal(t, data.FieldTypeNullableString, frame.Fields[3].Type())
+	require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[4].Type())
+	require.Equal(t, &gframer.FrameMetaCustom{Schema: []gframer.SchemaField{
+		{Name: "a", Type: "number", Inferred: true},
+		{Name: "b", Type: "boolean", Inferred: true},
+		{Name: "c", Type: "timestamp", Inferred: true},
+		{Name: "d", Type: "string", Inferred: true},
+		{Name: "e", Type: "number", Inferred: true},
+	}}, frame.Meta.Custom)
+	t.Run("without infer types", func(t *testing.T) {
+		frame, err := gframer.ToDataFrame(input, gframer.FramerOptions{})
+		require.Nil(t, err)
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		require.True(t, frame.Meta == nil || frame.Meta.Custom == nil)
+	})
+	t.Run("non finite numbers", func(t *testing.T) {
+		for _, values := range [][]interface{}{{"NaN", "inf"}, {"+Inf", "Infinity"}, {"-infinity", "nan"}, {"0x1p-2", "0X10p0"}} {
+			input := []interface{}{map[string]interface{}{"value": values[0]}, map[string]interface{}{"value": values[1]}}
+			frame, err := gframer.ToDataFrame(input, gframer.FramerOptions{InferTypes: true})
+			require.Nil(t, err)
+			require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type(), values)
+		}
+	})
+}
+
+func TestToDataFrameFlattenColumns(t *testing.T) {
+	input := `[{ "user": { "a": "x", "b": { "c": "2" } } }]`
+	options := gframer.FramerOptions{Flatten: gframer.FlattenOptions{Enabled: true}, Columns: []gframer.ColumnSelector{
+		{Selector: "user"},
+		{Selector: "user.b.c", Type: "number"},
+	}}
+	var out interface{}
+	require.Nil(t, json.Unmarshal([]byte(input), &out))
+	frame, err := gframer.ToDataFrame(out, options)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(frame.Fields))
+	require.Equal(t, "user.a", frame.Fields[0].Name)
+	require.Equal(t, "user.b.c", frame.Fields[1].Name)
+	require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+	builder, err := gframer.NewFrameBuilder("", []string{"user.a", "user.b.c"}, options)
+	require.Nil(t, err)
+	require.Nil(t, builder.AppendRow([]interface{}{"x", "2"}))
+	frame, err = builder.Frame()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(frame.Fields))
+	require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+}
+
+func TestToDataFrameDateOrder(t *testing.T) {
+	input := []interface{}{
+		map[string]interface{}{"date": "12/25/2022"},
+		map[string]interface{}{"date": "01/02/2022"},
+		map[string]interface{}{"date": "03/04/2022"},
+	}
+	options := gframer.FramerOptions{DateOrder: framerUtils.DateOrderDMY, Columns: []gframer.ColumnSelector{{Selector: "date", Type: "timestamp"}}}
+	frame, err := gframer.ToDataFrame(input, options)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+	require.Equal(t, time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+	require.Equal(t, time.Date(2022, 4, 3, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(2).(*time.Time))
+}
+
+func TestFrameBuilder(t *testing.T) {
+	rows := [][]interface{}{}
+	for i := 0; i < 1500; i++ {
+		rows = append(rows, []interface{}{fmt.Sprintf("%d", 1672531200+i*60), fmt.Sprintf("%d", i%7), fmt.Sprintf("%d", i)})
+	}
+	rows = append(rows, []interface{}{"1672621200000", 1.5, nil}, []interface{}{nil, true})
+	options := gframer.FramerOptions{FieldOrder: gframer.FieldOrderSource, Columns: []gframer.ColumnSelector{
+		{Selector: "time", Type: "timestamp_epoch_auto"},
+		{Selector: "mixed"},
+		{Selector: "value"},
+	}}
+	builder, err := gframer.NewFrameBuilder("foo", []string{"time", "mixed", "value"}, options)
+	require.Nil(t, err)
+	objects := []interface{}{}
+	for _, row := range rows {
+		require.Nil(t, builder.AppendRow(row))
+		object := gframer.NewOrderedMap()
+		for idx, name := range []string{"time", "mixed", "value"} {
+			if idx < len(row) {
+				object.Set(name, row[idx])
+			}
+		}
+		objects = append(objects, object)
+	}
+	frame, err := builder.Frame()
+	require.Nil(t, err)
+	options.FrameName = "foo"
+	wantFrame, err := gframer.ToDataFrame(objects, options)
+	require.Nil(t, err)
+	require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+	require.Equal(t, data.FieldTypeNullableString, frame.Fields[2].Type())
+	// the unit of the epoch is detected from all the values of the column, as ToDataFrame does
+	require.Equal(t, time.UnixMilli(1672531200).UTC(), frame.Fields[0].At(0).(*time.Time).UTC())
+	require.Equal(t, wantFrame, frame)
+}
@@ -0,0 +1,447 @@
+package gframer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
+)
+
+type valueKind int
+
+const (
+	valueKindNull valueKind = iota
+	valueKindBool
+	valueKindNumber
+	valueKindNumericString
+	valueKindString
+	valueKindTime
+	valueKindDuration
+	valueKindJSON
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case valueKindBool:
+		return "boolean"
+	case valueKindNumber:
+		return "number"
+	case valueKindNumericString:
+		return "numeric string"
+	case valueKindString:
+		return "string"
+	case valueKindTime:
+		return "time"
+	case valueKindDuration:
+		return "duration"
+	case valueKindJSON:
+		return "json"
+	default:
+		return "null"
+	}
+}
+
+func getValueKind(value interface{}) valueKind {
+	switch v := value.(type) {
+	case nil:
+		return valueKindNull
+	case bool:
+		return valueKindBool
+	case time.Time:
+		return valueKindTime
+	case time.Duration:
+		return valueKindDuration
+	case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		return valueKindNumber
+	case string:
+		if isNumericString(v) {
+			return valueKindNumericString
+		}
+		return valueKindString
+	default:
+		return valueKindJSON
+	}
+}
+
+// isNumericString reports whether the string is a finite decimal number. The other forms accepted by strconv.ParseFloat,
+// such as NaN, Inf, Infinity and the hexadecimal numbers, are kept as strings so that the columns of such words aren't numbers
+func isNumericString(s string) bool {
+	s = strings.TrimSpace(s)
+	f, err := strconv.ParseFloat(s, 64)
+	return err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) && !strings.ContainsAny(s, "xX")
+}
+
+// columnType is the type inferred for a column after looking at all of its values
+type columnType struct {
+	fieldType data.FieldType
+	kinds     []valueKind // distinct non null kinds in the order they were found
+}
+
+// mixed reports whether the values had to be widened to a common type
+func (c columnType) mixed() bool {
+	return len(c.kinds) > 1
+}
+
+func (c columnType) has(kind valueKind) bool {
+	for _, k := range c.kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// inferColumnType scans every value of the column and widens the type as needed.
+// bool < number < string, numeric strings are treated as numbers when mixed with numbers and anything mixed with objects or arrays becomes json.
+// Times and durations become time and int64 (nanoseconds) fields, or strings when mixed with other kinds.
+// With FramerOptions.InferIntegers, number columns holding only integers become int64 or uint64 fields
+func inferColumnType(values []interface{}, options FramerOptions) columnType {
+	ct := columnType{fieldType: data.FieldTypeNullableString}
+	for _, v := range values {
+		if kind := getValueKind(v); kind != valueKindNull && !ct.has(kind) {
+			ct.kinds = append(ct.kinds, kind)
+		}
+	}
+	switch {
+	case len(ct.kinds) == 0:
+		ct.fieldType = data.FieldTypeNullableString
+	case ct.has(valueKindJSON):
+		ct.fieldType = data.FieldTypeJSON
+	case ct.has(valueKindString), ct.mixed() && (ct.has(valueKindTime) || ct.has(valueKindDuration)):
+		ct.fieldType = data.FieldTypeNullableString
+	case ct.has(valueKindTime):
+		ct.fieldType = data.FieldTypeNullableTime
+	case ct.has(valueKindDuration):
+		ct.fieldType = data.FieldTypeNullableInt64
+	case ct.has(valueKindNumericString) && !ct.has(valueKindNumber):
+		ct.fieldType = data.FieldTypeNullableString
+	case ct.has(valueKindNumber):
+		ct.fieldType = data.FieldTypeNullableFloat64
+		if options.InferIntegers && !ct.has(valueKindBool) {
+			ct.fieldType = integerFieldType(values)
+		}
+	default:
+		ct.fieldType = data.FieldTypeNullableBool
+	}
+	return ct
+}
+
+// integerFieldType returns int64 or uint64 when all the values can be stored as such without loss, float64 otherwise
+func integerFieldType(values []interface{}) data.FieldType {
+	allInt64, allUint64 := true, true
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if _, ok := toInt64(v); !ok {
+			allInt64 = false
+		}
+		if _, ok := toUint64(v); !ok {
+			allUint64 = false
+		}
+	}
+	switch {
+	case allInt64:
+		return data.FieldTypeNullableInt64
+	case allUint64:
+		return data.FieldTypeNullableUint64
+	default:
+		return data.FieldTypeNullableFloat64
+	}
+}
+
+// maxSafeInteger is the largest integer a float64 can hold without losing precision
+const maxSafeInteger = 1 << 53
+
+// toInt64 converts the value to int64 only when it is an integer that fits in int64 without loss
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return toInt64(string(v))
+	case string:
+		out, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return out, err == nil
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > maxSafeInteger {
+			return 0, false
+		}
+		return int64(v), true
+	case float32:
+		return toInt64(float64(v))
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case time.Duration:
+		return int64(v), true
+	case uint, uint8, uint16, uint32, uint64:
+		if u, ok := toUint64(v); ok && u <= math.MaxInt64 {
+			return int64(u), true
+		}
+	}
+	return 0, false
+}
+
+// toUint64 converts the value to uint64 only when it is a non negative integer that fits in uint64 without loss
+func toUint64(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return toUint64(string(v))
+	case string:
+		out, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+		return out, err == nil
+	case uint:
+		return uint64(v), true
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case float64, float32, int, int8, int16, int32, int64:
+		if i, ok := toInt64(v); ok && i >= 0 {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// widenValue converts the value to the go type expected by the inferred field type
+func widenValue(value interface{}, fieldType data.FieldType) interface{} {
+	if fieldType == data.FieldTypeJSON {
+		if o, err := json.Marshal(value); err == nil {
+			return string(o)
+		}
+		return nil
+	}
+	if value == nil {
+		return nil
+	}
+	switch fieldType {
+	case data.FieldTypeNullableFloat64:
+		switch v := value.(type) {
+		case bool:
+			if v {
+				return float64(1)
+			}
+			return float64(0)
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f
+			}
+			return nil
+		default:
+			if f, ok := toFloat64(value); ok {
+				return f
+			}
+			return nil
+		}
+	case data.FieldTypeNullableInt64:
+		if i, ok := toInt64(value); ok {
+			return i
+		}
+		return nil
+	case data.FieldTypeNullableUint64:
+		if u, ok := toUint64(value); ok {
+			return u
+		}
+		return nil
+	case data.FieldTypeNullableTime:
+		if v, ok := value.(time.Time); ok {
+			return v
+		}
+		return nil
+	case data.FieldTypeNullableString:
+		switch v := value.(type) {
+		case string:
+			return v
+		case time.Time:
+			return v.Format(time.RFC3339Nano)
+		}
+		return fmt.Sprintf("%v", value)
+	case data.FieldTypeNullableBool:
+		if v, ok := value.(bool); ok {
+			return v
+		}
+		return nil
+	}
+	return value
+}
+
+// newInferredField creates a field of the inferred column type with all the values widened to that type.
+// Objects and arrays are stored as json strings unless json fields are requested through the options
+func newInferredField(name string, values []interface{}, ct columnType, options FramerOptions) *data.Field {
+	if isJSONColumn(name, options) || (ct.fieldType == data.FieldTypeJSON && options.JSONFields) {
+		return newJSONField(name, values)
+	}
+	fieldType := ct.fieldType
+	if fieldType == data.FieldTypeJSON {
+		fieldType = data.FieldTypeNullableString
+	}
+	field := data.NewFieldFromFieldType(fieldType, len(values))
+	field.Name = name
+	for i, v := range values {
+		field.Set(i, ToPointer(widenValue(v, ct.fieldType)))
+	}
+	if fieldType == data.FieldTypeNullableInt64 && ct.has(valueKindDuration) {
+		field.Config = &data.FieldConfig{Unit: "ns"}
+	}
+	return field
+}
+
+// newJSONField creates a json field with each value marshaled as is. Null values remain null
+func newJSONField(name string, values []interface{}) *data.Field {
+	field := data.NewFieldFromFieldType(data.FieldTypeNullableJSON, len(values))
+	field.Name = name
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if o, err := json.Marshal(v); err == nil {
+			field.Set(i, ToPointer(json.RawMessage(o)))
+		}
+	}
+	return field
+}
+
+// isJSONColumn checks whether the field is forced to be a json field through the column selectors
+func isJSONColumn(name string, options FramerOptions) bool {
+	idx := columnIndex(name, options)
+	return idx >= 0 && options.Columns[idx].Type == "json"
+}
+
+// appendInferredField adds the field to the frame and reports the widening done, if any, as a frame notice
+func appendInferredField(frame *data.Frame, name string, values []interface{}, ct columnType, options FramerOptions) {
+	frame.Fields = append(frame.Fields, newInferredField(name, values, ct, options))
+	if ct.mixed() {
+		addNotice(frame, inferenceNotice(name, ct))
+	}
+}
+
+// inferenceNotice describes the widening done for a column with mixed value types
+func inferenceNotice(name string, ct columnType) data.Notice {
+	kinds := make([]string, len(ct.kinds))
+	for i, k := range ct.kinds {
+		kinds[i] = k.String()
+	}
+	target := "string"
+	switch ct.fieldType {
+	case data.FieldTypeJSON:
+		target = "json"
+	case data.FieldTypeNullableFloat64, data.FieldTypeNullableInt64, data.FieldTypeNullableUint64:
+		target = "number"
+	case data.FieldTypeNullableBool:
+		target = "boolean"
+	case data.FieldTypeNullableTime:
+		target = "time"
+	}
+	return data.Notice{
+		Severity: data.NoticeSeverityInfo,
+		Text:     fmt.Sprintf("field %q has mixed value types (%s). values converted to %s", name, strings.Join(kinds, ", "), target),
+	}
+}
+
+// inferStringColumn chooses the number, boolean or timestamp type for the columns where every non empty value is a string of that type,
+// such as the csv columns. The returned column has the type, and the layout of the timestamps when found. Returns false for the other columns.
+// Booleans are only true and false, so that the columns of 0 and 1 stay numbers
+func inferStringColumn(values []interface{}, column ColumnSelector) (ColumnSelector, bool) {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			return column, false
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			strs = append(strs, s)
+		}
+	}
+	if len(strs) == 0 {
+		return column, false
+	}
+	isNumber, isBool := true, true
+	for _, s := range strs {
+		if getValueKind(s) != valueKindNumericString {
+			isNumber = false
+		}
+		if !strings.EqualFold(s, "true") && !strings.EqualFold(s, "false") {
+			isBool = false
+		}
+	}
+	switch {
+	case isNumber:
+		column.Type = "number"
+	case isBool:
+		column.Type = "boolean"
+	default:
+		layout, ok := framerUtils.DetectLayout(strs)
+		if !ok {
+			return column, false
+		}
+		column.Type = "timestamp"
+		if column.TimeFormat == "" && (column.DateOrder == "" || column.DateOrder == framerUtils.DateOrderMDY) {
+			// the detected layout resolves the ambiguous dates using all the values
+			column.TimeFormat = layout
+		}
+	}
+	return column, true
+}
+
+// nullEmptyStrings returns the values with the empty strings set to null
+func nullEmptyStrings(values []interface{}) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); !ok || strings.TrimSpace(s) != "" {
+			out[i] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+package gframer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// maxReportedFailures is the number of failed rows listed in the conversion notice of a field
+const maxReportedFailures = 5
+
+// conversionFailures collects the values of a field that couldn't be converted to the type of the column
+type conversionFailures struct {
+	column string
+	kind   string
+	count  int
+	rows   []int
+	values []interface{}
+	first  error // error of the first failure
+}
+
+func newConversionFailures(column string, kind string) *conversionFailures {
+	return &conversionFailures{column: column, kind: kind}
+}
+
+// add records the failure. null and empty values are treated as missing values, not as failures
+func (f *conversionFailures) add(row int, value interface{}, err error) {
+	if value == nil || value == "" {
+		return
+	}
+	if f.count == 0 {
+		f.first = err
+	}
+	f.count++
+	if len(f.rows) < maxReportedFailures {
+		f.rows = append(f.rows, row)
+		f.values = append(f.values, value)
+	}
+}
+
+// err returns the first failure as ErrConversion
+func (f *conversionFailures) err() error {
+	if f.count == 0 {
+		return nil
+	}
+	return &ErrConversion{Column: f.column, Row: f.rows[0], Value: f.values[0], Type: f.kind, Err: f.first}
+}
+
+func (f *conversionFailures) notice() data.Notice {
+	examples := make([]string, len(f.rows))
+	for idx, row := range f.rows {
+		examples[idx] = fmt.Sprintf("row %d: %#v", row, f.values[idx])
+	}
+	if f.count > len(f.rows) {
+		examples = append(examples, "...")
+	}
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("%d value(s) of field %q couldn't be converted to %s and are set to null (%s)", f.count, f.column, f.kind, strings.Join(examples, ", ")),
+	}
+}
+
+// report adds the failures to the frame notices. In strict mode, the failures are returned as error instead
+func (f *conversionFailures) report(frame *data.Frame, options FramerOptions) error {
+	if f.count == 0 {
+		return nil
+	}
+	if options.Strict {
+		return f.err()
+	}
+	addNotice(frame, f.notice())
+	return nil
+}
+
+func addNotice(frame *data.Frame, notice data.Notice) {
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.Notices = append(frame.Meta.Notices, notice)
+}
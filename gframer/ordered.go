@@ -0,0 +1,187 @@
+package gframer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// FieldOrder controls the order of the fields in the resulting frame
+type FieldOrder string
+
+const (
+	FieldOrderAlphabetical FieldOrder = "alphabetical" // fields sorted by name. This is the default
+	FieldOrderSource       FieldOrder = "source"       // fields in the order they appear in the input, first seen across rows
+	FieldOrderColumns      FieldOrder = "columns"      // fields in the order listed in FramerOptions.Columns, followed by the rest in source order
+)
+
+// OrderedMap is a json object that remembers the order in which its keys were added
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: map[string]interface{}{}}
+}
+
+// Set adds or updates the key. Updating an existing key keeps its original position
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+func (m *OrderedMap) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// ToMap returns the values as plain map. Key order is lost
+func (m *OrderedMap) ToMap() map[string]interface{} {
+	out := make(map[string]interface{}, len(m.values))
+	for k, v := range m.values {
+		out[k] = v
+	}
+	return out
+}
+
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for idx, key := range m.keys {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// DecodeJSON works like json.Unmarshal into interface{}, except json objects are decoded as *OrderedMap so that the key order of the source is preserved
+// and numbers are decoded as json.Number so that large integers don't lose precision
+func DecodeJSON(input []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+	out, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+	return out, nil
+}
+
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		out := NewOrderedMap()
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid object key %v", keyToken)
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			out.Set(key, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return out, nil
+	case '[':
+		out := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unexpected delimiter %v", delim)
+}
+
+// asObject returns the keys and values of a json object. Keys of plain maps are sorted as they carry no order
+func asObject(input interface{}) (keys []string, values map[string]interface{}, ok bool) {
+	switch in := input.(type) {
+	case *OrderedMap:
+		if in == nil {
+			return nil, nil, false
+		}
+		return in.Keys(), in.values, true
+	case map[string]interface{}:
+		return sortedKeys(in), in, true
+	}
+	return nil, nil, false
+}
+
+// orderFieldNames orders the field names (given in source order) as per the FramerOptions.FieldOrder
+func orderFieldNames(names []string, options FramerOptions) []string {
+	out := append([]string{}, names...)
+	switch options.FieldOrder {
+	case FieldOrderSource:
+		return out
+	case FieldOrderColumns:
+		rank := map[string]int{}
+		for _, name := range out {
+			if idx := columnIndex(name, options); idx >= 0 {
+				rank[name] = idx
+			}
+		}
+		sort.SliceStable(out, func(i, j int) bool {
+			ri, iok := rank[out[i]]
+			rj, jok := rank[out[j]]
+			if iok && jok {
+				return ri < rj
+			}
+			return iok && !jok
+		})
+		return out
+	default:
+		sort.Strings(out)
+		return out
+	}
+}
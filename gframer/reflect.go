@@ -0,0 +1,230 @@
+package gframer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// frameTag is the parsed form of the `frame:"name,type=timestamp,format=2006-01-02,omitempty"` struct tag
+type frameTag struct {
+	name       string
+	kind       string
+	timeFormat string
+	omitEmpty  bool
+	skip       bool
+}
+
+func parseFrameTag(f reflect.StructField) frameTag {
+	tag := frameTag{name: f.Name}
+	value, ok := f.Tag.Lookup("frame")
+	if !ok {
+		// fallback to the json tag name so that the structs used for api responses work as is
+		if jsonName := strings.Split(f.Tag.Get("json"), ",")[0]; jsonName == "-" {
+			tag.skip = true
+		} else if jsonName != "" {
+			tag.name = jsonName
+		}
+		return tag
+	}
+	if value == "-" {
+		tag.skip = true
+		return tag
+	}
+	parts := strings.Split(value, ",")
+	if parts[0] != "" {
+		tag.name = parts[0]
+	}
+	for _, part := range parts[1:] {
+		switch {
+		case part == "omitempty":
+			tag.omitEmpty = true
+		case strings.HasPrefix(part, "type="):
+			tag.kind = strings.TrimPrefix(part, "type=")
+		case strings.HasPrefix(part, "format="):
+			tag.timeFormat = strings.TrimPrefix(part, "format=")
+		}
+	}
+	return tag
+}
+
+// normalizeInput converts the structs, pointers, typed slices and typed maps of the input into the generic
+// representation used by the framer. The types given in the struct tags are applied as column selectors
+func normalizeInput(input interface{}, options FramerOptions) (interface{}, FramerOptions) {
+	switch in := input.(type) {
+	case nil, string, float64, bool, json.Number, *OrderedMap, map[string]interface{}:
+		return input, options
+	case []interface{}:
+		if !needsNormalization(in) {
+			return input, options
+		}
+	}
+	n := &normalizer{}
+	out := n.normalize(reflect.ValueOf(input), true)
+	if rv := reflect.Indirect(reflect.ValueOf(input)); rv.Kind() == reflect.Struct && rv.Type() != timeType {
+		// single struct is framed as single row so that the column types of the tags are respected
+		out = []interface{}{out}
+	}
+	return out, n.apply(options)
+}
+
+func needsNormalization(rows []interface{}) bool {
+	for _, row := range rows {
+		switch row.(type) {
+		case nil, string, float64, bool, json.Number, *OrderedMap, map[string]interface{}, []interface{}:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// normalizer walks the input using reflection and remembers the columns of the row structs
+type normalizer struct {
+	columns []ColumnSelector
+	typed   bool
+}
+
+func (n *normalizer) normalize(v reflect.Value, isRow bool) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Type() {
+	case timeType, durationType, jsonNumberType, rawMessageType:
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return n.normalize(v.Elem(), isRow)
+	case reflect.Struct:
+		out := NewOrderedMap()
+		n.normalizeStruct(v, out, isRow)
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = n.normalize(iter.Value(), false)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		out := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = n.normalize(v.Index(i), isRow)
+		}
+		return out
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+	return nil
+}
+
+func (n *normalizer) normalizeStruct(v reflect.Value, out *OrderedMap, isRow bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		tag := parseFrameTag(f)
+		if tag.skip {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous && f.Tag.Get("frame") == "" {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				// fields of the embedded structs are promoted, even when the embedded struct itself is unexported
+				n.normalizeStruct(fv, out, isRow)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if k := f.Type.Kind(); k == reflect.Func || k == reflect.Chan {
+			// skipped like encoding/json does, as they have no value to frame
+			continue
+		}
+		if isRow {
+			n.addColumn(tag)
+		}
+		if tag.omitEmpty && fv.IsZero() {
+			continue
+		}
+		out.Set(tag.name, n.normalize(fv, false))
+	}
+}
+
+func (n *normalizer) addColumn(tag frameTag) {
+	for _, c := range n.columns {
+		if c.Selector == tag.name {
+			return
+		}
+	}
+	n.columns = append(n.columns, ColumnSelector{Selector: tag.name, Type: tag.kind, TimeFormat: tag.timeFormat})
+	if tag.kind != "" {
+		n.typed = true
+	}
+}
+
+// apply adds the column types found in the struct tags to the options. When no columns are given, all the
+// struct fields become columns. Otherwise only the columns without a type are updated from the tags
+func (n *normalizer) apply(options FramerOptions) FramerOptions {
+	if !n.typed {
+		return options
+	}
+	if len(options.Columns) == 0 {
+		options.Columns = n.columns
+		return options
+	}
+	columns := make([]ColumnSelector, len(options.Columns))
+	for idx, c := range options.Columns {
+		columns[idx] = c
+		if c.Type != "" {
+			continue
+		}
+		for _, tc := range n.columns {
+			if tc.Selector == c.Selector {
+				columns[idx].Type = tc.Type
+				columns[idx].TimeFormat = tc.TimeFormat
+			}
+		}
+	}
+	options.Columns = columns
+	return options
+}
@@ -0,0 +1,87 @@
+package gframer
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// arraysToRows converts rows given as arrays such as `[["time","value"],[1,2]]` into objects.
+// The field names come from the first row, from FramerOptions.Header or from the column selectors given by index such as `0`.
+// Input that is not array of arrays, or when none of the above options are given, is returned as is
+func arraysToRows(input interface{}, options FramerOptions) (interface{}, FramerOptions) {
+	rows, ok := input.([]interface{})
+	if !ok || !isArrayRows(rows) {
+		return input, options
+	}
+	if !options.FirstRowHeader && len(options.Header) == 0 && !hasIndexSelectors(options.Columns) {
+		return input, options
+	}
+	header := options.Header
+	if options.FirstRowHeader {
+		header = []string{}
+		for len(rows) > 0 {
+			first, ok := rows[0].([]interface{})
+			rows = rows[1:]
+			if ok {
+				for _, h := range first {
+					header = append(header, fmt.Sprintf("%v", h))
+				}
+				break
+			}
+		}
+	}
+	columns := make([]ColumnSelector, len(options.Columns))
+	for idx, c := range options.Columns {
+		columns[idx] = c
+		if i, err := strconv.Atoi(c.Selector); err == nil && i >= 0 && i < len(header) && header[i] != "" {
+			columns[idx].Selector = header[i]
+		}
+	}
+	options.Columns = columns
+	out := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		items, ok := row.([]interface{})
+		if !ok {
+			continue
+		}
+		item := NewOrderedMap()
+		for idx, value := range items {
+			item.Set(rowFieldName(idx, header, columns), value)
+		}
+		out = append(out, item)
+	}
+	return out, options
+}
+
+// rowFieldName returns the header name of the array index, or the index itself when there is no header. Names with an alias in the column selectors are replaced by the alias
+func rowFieldName(idx int, header []string, columns []ColumnSelector) string {
+	name := strconv.Itoa(idx)
+	if idx < len(header) && header[idx] != "" {
+		name = header[idx]
+	}
+	for _, c := range columns {
+		if c.Alias != "" && c.Selector == name {
+			return c.Alias
+		}
+	}
+	return name
+}
+
+func isArrayRows(rows []interface{}) bool {
+	for _, row := range rows {
+		if row != nil {
+			_, ok := row.([]interface{})
+			return ok
+		}
+	}
+	return false
+}
+
+func hasIndexSelectors(columns []ColumnSelector) bool {
+	for _, c := range columns {
+		if _, err := strconv.Atoi(c.Selector); err == nil {
+			return true
+		}
+	}
+	return false
+}
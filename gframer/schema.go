@@ -0,0 +1,75 @@
+package gframer
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameMetaCustom is the custom meta of the frames, set when FramerOptions.InferTypes is enabled
+type FrameMetaCustom struct {
+	Schema []SchemaField `json:"schema,omitempty"` // types of the frame fields, in the order of the fields
+}
+
+// SchemaField is the type of a frame field. Type is the name of the converter such as number, boolean, timestamp or string
+type SchemaField struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	TimeFormat string `json:"timeFormat,omitempty"` // layout detected for the inferred timestamps
+	Inferred   bool   `json:"inferred"`             // the type is inferred from the values, instead of given by the columns
+}
+
+// appendUntypedField appends the field of a column without type. With FramerOptions.InferTypes, the columns of strings are converted to the type inferred from their values
+func appendUntypedField(frame *data.Frame, name string, values []interface{}, ct columnType, column ColumnSelector, options FramerOptions) error {
+	if !options.InferTypes {
+		appendInferredField(frame, name, values, ct, options)
+		return nil
+	}
+	if column.TimeZone == "" {
+		column.TimeZone = options.TimeZone
+	}
+	if column.DateOrder == "" {
+		column.DateOrder = options.DateOrder
+	}
+	if inferred, ok := inferStringColumn(values, column); ok {
+		converter, _ := GetConverter(inferred.Type)
+		field, failures := newConvertedField(name, nullEmptyStrings(values), inferred, converter)
+		frame.Fields = append(frame.Fields, field)
+		addSchemaField(frame, SchemaField{Name: name, Type: inferred.Type, TimeFormat: inferred.TimeFormat, Inferred: true})
+		return failures.report(frame, options)
+	}
+	appendInferredField(frame, name, values, ct, options)
+	addSchemaField(frame, SchemaField{Name: name, Type: schemaType(ct.fieldType), Inferred: true})
+	return nil
+}
+
+// addConvertedSchemaField reports the type of a field converted with the type of its column
+func addConvertedSchemaField(frame *data.Frame, name string, converter Converter, options FramerOptions) {
+	if options.InferTypes {
+		addSchemaField(frame, SchemaField{Name: name, Type: converter.Name()})
+	}
+}
+
+func addSchemaField(frame *data.Frame, field SchemaField) {
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	custom, ok := frame.Meta.Custom.(*FrameMetaCustom)
+	if !ok {
+		custom = &FrameMetaCustom{}
+		frame.Meta.Custom = custom
+	}
+	custom.Schema = append(custom.Schema, field)
+}
+
+func schemaType(fieldType data.FieldType) string {
+	switch fieldType {
+	case data.FieldTypeNullableFloat64, data.FieldTypeNullableInt64, data.FieldTypeNullableUint64:
+		return "number"
+	case data.FieldTypeNullableBool:
+		return "boolean"
+	case data.FieldTypeNullableTime:
+		return "timestamp"
+	case data.FieldTypeJSON, data.FieldTypeNullableJSON:
+		return "json"
+	}
+	return "string"
+}
@@ -3,12 +3,12 @@ package jsonFramer
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/blues/jsonata-go"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/tidwall/gjson"
+	"github.com/yesoreyeram/grafana-framer/framerUtils"
 	"github.com/yesoreyeram/grafana-framer/gframer"
 )
 
@@ -20,26 +20,64 @@ const (
 )
 
 type JSONFramerOptions struct {
-	FramerType   FramerType // `gjson` | `sqlite3`
-	SQLite3Query string
-	FrameName    string
-	RootSelector string
-	Columns      []ColumnSelector
+	FramerType     FramerType // `gjson` | `sqlite3`
+	SQLite3Query   string
+	FrameName      string
+	RootSelector   string
+	Columns        []ColumnSelector
+	FieldOrder     gframer.FieldOrder // `alphabetical` | `source` | `columns`
+	Flatten        gframer.FlattenOptions
+	InferIntegers  bool                  // emit int64/uint64 fields for the columns where every value is an integer
+	JSONFields     bool                  // emit objects and arrays as json fields instead of json strings
+	Columnar       gframer.ColumnarMode  // `auto` | `force`. frame objects of column arrays such as {"a":[1,2],"b":[3,4]} as one row per index
+	Header         []string              // field names of the rows given as arrays such as [[1,"a"],[2,"b"]]
+	FirstRowHeader bool                  // use the first row as field names when the rows are given as arrays
+	Strict         bool                  // fail when a value can't be converted to the column type instead of setting null
+	TimeZone       string                // default IANA time zone name of the timestamps without zone information
+	DateOrder      framerUtils.DateOrder // `MDY` | `DMY` | `YMD`. default order of the ambiguous dates such as 01/02/2006
 }
 
 type ColumnSelector struct {
-	Selector   string
-	Alias      string
-	Type       string
-	TimeFormat string
+	Selector    string
+	Alias       string
+	Type        string
+	TimeFormat  string
+	TrueValues  []string
+	FalseValues []string
+	TimeZone    string
+	DateOrder   framerUtils.DateOrder
+	// options of the number type for the numbers given as strings
+	DecimalSeparator   string
+	ThousandsSeparator string
+	StripCurrency      bool
+	StripPercent       bool
+	AccountingNegative bool
+}
+
+func (c ColumnSelector) toFramerColumn() gframer.ColumnSelector {
+	return gframer.ColumnSelector{
+		Alias:              c.Alias,
+		Selector:           c.Selector,
+		Type:               c.Type,
+		TimeFormat:         c.TimeFormat,
+		TrueValues:         c.TrueValues,
+		FalseValues:        c.FalseValues,
+		TimeZone:           c.TimeZone,
+		DateOrder:          c.DateOrder,
+		DecimalSeparator:   c.DecimalSeparator,
+		ThousandsSeparator: c.ThousandsSeparator,
+		StripCurrency:      c.StripCurrency,
+		StripPercent:       c.StripPercent,
+		AccountingNegative: c.AccountingNegative,
+	}
 }
 
 func JsonStringToFrame(jsonString string, options JSONFramerOptions) (frame *data.Frame, err error) {
 	if strings.Trim(jsonString, " ") == "" {
-		return frame, errors.New("empty json received")
+		return frame, gframer.ErrEmptyInput
 	}
 	if !gjson.Valid(jsonString) {
-		return frame, errors.New("invalid json response received")
+		return frame, invalidJSONError(jsonString, nil)
 	}
 	outString := jsonString
 	switch options.FramerType {
@@ -54,13 +92,23 @@ func JsonStringToFrame(jsonString string, options JSONFramerOptions) (frame *dat
 		if err != nil {
 			return frame, err
 		}
-		outString, err = getColumnValuesFromResponseString(outString, options.Columns)
-		if err != nil {
-			return frame, err
+		if len(options.Columns) == 0 || options.Flatten.Enabled || (options.FirstRowHeader || len(options.Header) > 0) && hasArrayRows(outString) {
+			// the columns of the array rows are selected by the header names, after the framer converts the rows to objects.
+			// With flatten, the columns are selected by the flattened names, after the framer flattens the rows
+			return getFrameFromResponseString(outString, options)
 		}
-		return getFrameFromResponseString(outString, options)
+		columns := make([]ColumnSelector, len(options.Columns))
+		for idx, c := range options.Columns {
+			if c.TimeZone == "" {
+				c.TimeZone = options.TimeZone
+			}
+			if c.DateOrder == "" {
+				c.DateOrder = options.DateOrder
+			}
+			columns[idx] = c
+		}
+		return getFrameFromValues(getColumnValuesFromResponseString(outString, columns, options.Columnar), options)
 	}
-	return getFrameFromResponseString(jsonString, options)
 }
 
 func GetRootData(jsonString string, rootSelector string) (string, error) {
@@ -69,85 +117,165 @@ func GetRootData(jsonString string, rootSelector string) (string, error) {
 		if r.Exists() {
 			return r.String(), nil
 		}
-		if e := jsonata.MustCompile(rootSelector); e != nil {
+		e, err := jsonata.Compile(rootSelector)
+		if err == nil {
 			var data interface{}
-			err := json.Unmarshal([]byte(jsonString), &data)
+			err = json.Unmarshal([]byte(jsonString), &data)
 			if err != nil {
-				return "", err
+				return "", invalidJSONError(jsonString, err)
 			}
-			if res, err := e.Eval(data); err == nil {
-				if r, err := json.Marshal(res); err == nil {
+			var res interface{}
+			if res, err = e.Eval(data); err == nil {
+				var r []byte
+				if r, err = json.Marshal(res); err == nil {
 					return string(r), nil
 				}
 			}
 		}
-		return "", errors.New("root object doesn't exist in the response. Root selector:" + rootSelector)
+		return "", &gframer.ErrRootNotFound{Selector: rootSelector, Err: err}
 
 	}
 	return jsonString, nil
 
 }
 
-func getColumnValuesFromResponseString(responseString string, columns []ColumnSelector) (string, error) {
-	if len(columns) > 0 {
-		outString := responseString
-		result := gjson.Parse(outString)
-		out := []map[string]interface{}{}
-		if result.IsArray() {
-			result.ForEach(func(key, value gjson.Result) bool {
-				oi := map[string]interface{}{}
-				for _, col := range columns {
-					name := col.Alias
-					if name == "" {
-						name = col.Selector
-					}
-					oi[name] = convertFieldValueType(gjson.Get(value.Raw, col.Selector).Value(), col)
-				}
-				out = append(out, oi)
-				return true
-			})
+// getColumnValuesFromResponseString picks the values of the columns from each row of the response.
+// The values are converted to the column type so that the rows can be framed without json round trip
+func getColumnValuesFromResponseString(responseString string, columns []ColumnSelector, columnar gframer.ColumnarMode) interface{} {
+	result := gjson.Parse(responseString)
+	out := []interface{}{}
+	if result.IsArray() {
+		result.ForEach(func(key, value gjson.Result) bool {
+			out = append(out, getColumnValues(value, columns, true))
+			return true
+		})
+	}
+	if !result.IsArray() && result.IsObject() {
+		if columnar != gframer.ColumnarModeNone {
+			// keep the object as is so that its column arrays can be framed as rows. The values are converted by the framer after that
+			return getColumnValues(result, columns, false)
 		}
-		if !result.IsArray() && result.IsObject() {
-			oi := map[string]interface{}{}
-			for _, col := range columns {
-				name := col.Alias
-				if name == "" {
-					name = col.Selector
-				}
-				oi[name] = convertFieldValueType(gjson.Get(result.Raw, col.Selector).Value(), col)
-			}
-			out = append(out, oi)
+		out = append(out, getColumnValues(result, columns, true))
+	}
+	return out
+}
+
+// hasArrayRows reports whether the response is an array of arrays such as [["time","value"],[1,2]]
+func hasArrayRows(responseString string) bool {
+	result := gjson.Parse(responseString)
+	if !result.IsArray() {
+		return false
+	}
+	isArray := false
+	result.ForEach(func(key, value gjson.Result) bool {
+		if value.Type == gjson.Null {
+			return true
 		}
-		a, err := json.Marshal(out)
-		if err != nil {
-			return "", err
+		isArray = value.IsArray()
+		return false
+	})
+	return isArray
+}
+
+func getColumnValues(row gjson.Result, columns []ColumnSelector, convert bool) *gframer.OrderedMap {
+	oi := gframer.NewOrderedMap()
+	for _, col := range columns {
+		name := col.Alias
+		if name == "" {
+			name = col.Selector
+		}
+		value := getValue(gjson.Get(row.Raw, col.Selector))
+		if convert {
+			value = convertFieldValueType(value, col)
 		}
-		return string(a), nil
+		oi.Set(name, value)
 	}
-	return responseString, nil
+	return oi
 }
 
 func getFrameFromResponseString(responseString string, options JSONFramerOptions) (frame *data.Frame, err error) {
 	var out interface{}
-	err = json.Unmarshal([]byte(responseString), &out)
+	switch options.FieldOrder {
+	case gframer.FieldOrderSource, gframer.FieldOrderColumns:
+		out, err = gframer.DecodeJSON([]byte(responseString))
+	default:
+		decoder := json.NewDecoder(strings.NewReader(responseString))
+		decoder.UseNumber()
+		err = decoder.Decode(&out)
+	}
 	if err != nil {
-		return frame, fmt.Errorf("error while un-marshaling response. %s", err.Error())
+		return frame, invalidJSONError(responseString, err)
 	}
+	return getFrameFromValues(out, options)
+}
+
+func getFrameFromValues(out interface{}, options JSONFramerOptions) (frame *data.Frame, err error) {
 	columns := []gframer.ColumnSelector{}
 	for _, c := range options.Columns {
-		columns = append(columns, gframer.ColumnSelector{
-			Alias:      c.Alias,
-			Selector:   c.Selector,
-			Type:       c.Type,
-			TimeFormat: c.TimeFormat,
-		})
+		columns = append(columns, c.toFramerColumn())
 	}
 	return gframer.ToDataFrame(out, gframer.FramerOptions{
-		FrameName: options.FrameName,
-		Columns:   columns,
+		FrameName:      options.FrameName,
+		Columns:        columns,
+		FieldOrder:     options.FieldOrder,
+		Flatten:        options.Flatten,
+		InferIntegers:  options.InferIntegers,
+		JSONFields:     options.JSONFields,
+		Columnar:       options.Columnar,
+		Header:         options.Header,
+		FirstRowHeader: options.FirstRowHeader,
+		Strict:         options.Strict,
+		TimeZone:       options.TimeZone,
+		DateOrder:      options.DateOrder,
 	})
 }
 
+// invalidJSONError describes where the json input is invalid. When err is nil, the input is parsed to find the syntax error
+func invalidJSONError(jsonString string, err error) error {
+	if err == nil {
+		var out interface{}
+		err = json.Unmarshal([]byte(jsonString), &out)
+	}
+	out := &gframer.ErrInvalidInput{Format: "json", Err: err}
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		out.Offset = syntaxError.Offset
+		if offset := int(syntaxError.Offset); offset <= len(jsonString) {
+			out.Line = strings.Count(jsonString[:offset], "\n") + 1
+		}
+	}
+	return out
+}
+
+// getValue returns the value of the result. Numbers, including the numbers within objects and arrays, are returned as json.Number to retain their precision
+func getValue(result gjson.Result) interface{} {
+	switch {
+	case result.Type == gjson.Number:
+		return json.Number(result.Raw)
+	case result.IsObject() || result.IsArray():
+		var out interface{}
+		decoder := json.NewDecoder(strings.NewReader(result.Raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&out); err == nil {
+			return out
+		}
+	}
+	return result.Value()
+}
+
+// convertFieldValueType converts the value using the converter registered for the column type.
+// Values that can't be converted are returned as is, so that the framer reports them as conversion failures
 func convertFieldValueType(input interface{}, col ColumnSelector) interface{} {
+	converter, ok := gframer.GetConverter(col.Type)
+	if !ok || input == nil {
+		return input
+	}
+	if _, ok := converter.(gframer.ColumnConverter); ok {
+		// converted by the framer, which creates the converter from all the values of the column
+		return input
+	}
+	if out, err := converter.Convert(input, col.toFramerColumn()); err == nil && out != nil {
+		return out
+	}
 	return input
 }
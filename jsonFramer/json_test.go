@@ -2,7 +2,6 @@ package jsonFramer_test
 
 import (
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"strings"
 	"testing"
@@ -10,6 +9,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-framer/gframer"
 	"github.com/yesoreyeram/grafana-framer/jsonFramer"
 )
 
@@ -21,18 +21,20 @@ func TestJsonStringToFrame(t *testing.T) {
 		refId          string
 		rootSelector   string
 		columns        []jsonFramer.ColumnSelector
+		fieldOrder     gframer.FieldOrder
+		flatten        gframer.FlattenOptions
+		inferIntegers  bool
+		jsonFields     bool
+		columnar       gframer.ColumnarMode
+		firstRowHeader bool
+		header         []string
 		wantFrame      *data.Frame
 		wantErr        error
 	}{
 		{
 			name:           "empty string should throw error",
 			responseString: "",
-			wantErr:        errors.New("empty json received"),
-		},
-		{
-			name:           "invalid json should throw error",
-			responseString: "{",
-			wantErr:        errors.New("invalid json response received"),
+			wantErr:        gframer.ErrEmptyInput,
 		},
 		{
 			name:           "valid json object should not throw error",
@@ -226,17 +228,251 @@ func TestJsonStringToFrame(t *testing.T) {
 			}`,
 			rootSelector: "$sum(sss.bar1)",
 		},
+		{
+			name: "field order source",
+			responseString: `[
+				{ "time": "2011-01-01T00:00:00.000Z", "host": "foo", "value": 1 },
+				{ "time": "2012-01-01T00:00:00.000Z", "host": "bar", "value": 2, "region": "eu" }
+			]`,
+			fieldOrder: gframer.FieldOrderSource,
+		},
+		{
+			name: "field order columns",
+			responseString: `[
+				{ "time": "2011-01-01T00:00:00.000Z", "host": "foo", "value": 1 },
+				{ "time": "2012-01-01T00:00:00.000Z", "host": "bar", "value": 2, "region": "eu" }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "value"},
+				{Selector: "time", Type: "timestamp"},
+				{Selector: "host", Alias: "server"},
+			},
+			fieldOrder: gframer.FieldOrderColumns,
+		},
+		{
+			name: "flatten",
+			responseString: `[
+				{ "id": 1, "user": { "name": "foo", "address": { "city": "london", "zip": 123 } } },
+				{ "id": 2, "user": { "name": "bar", "address": { "city": "paris" } } }
+			]`,
+			flatten: gframer.FlattenOptions{Enabled: true},
+		},
+		{
+			name: "flatten with columns",
+			responseString: `[
+				{ "id": 1, "user": { "name": "foo", "address": { "city": "london", "zip": 123 } } },
+				{ "id": 2, "user": { "name": "bar", "address": { "city": "paris" } } }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "id"},
+				{Selector: "user.address.city", Alias: "city"},
+				{Selector: "user.address.zip", Type: "string"},
+			},
+			flatten: gframer.FlattenOptions{Enabled: true},
+		},
+		{
+			name: "flatten with separator and columns",
+			responseString: `[
+				{ "id": 1, "user": { "name": "foo", "address": { "city": "london", "zip": 123 } } },
+				{ "id": 2, "user": { "name": "bar", "address": { "city": "paris" } } }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "id"},
+				{Selector: "user_address_city", Alias: "city"},
+				{Selector: "user_address_zip", Type: "string"},
+			},
+			flatten: gframer.FlattenOptions{Enabled: true, Separator: "_"},
+		},
+		{
+			name: "flatten with column selector prefix",
+			responseString: `[
+				{ "id": 1, "user": { "name": "foo", "address": { "city": "london", "zip": 123 } } },
+				{ "id": 2, "user": { "name": "bar", "address": { "city": "paris" } } }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "user"},
+			},
+			flatten: gframer.FlattenOptions{Enabled: true, MaxDepth: 1},
+		},
+		{
+			name: "large integers",
+			responseString: `[
+				{ "id": 9007199254740993, "counter": 18446744073709551615, "value": 1.5, "nanos": 1645093800123456789 },
+				{ "id": -9007199254740993, "counter": 1, "value": 2, "nanos": 1645093800987654321 }
+			]`,
+			inferIntegers: true,
+		},
+		{
+			name: "int64 and uint64 types",
+			responseString: `[
+				{ "id": 9007199254740993, "counter": "18446744073709551615", "value": 1.5 },
+				{ "id": "12", "counter": 1, "value": 2 }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "id", Type: "int64"},
+				{Selector: "counter", Type: "uint64"},
+				{Selector: "value", Type: "int64"},
+			},
+		},
+		{
+			name: "boolean type",
+			responseString: `[
+				{ "enabled": true, "active": "yes", "status": "up", "count": 1 },
+				{ "enabled": false, "active": "N", "status": "down", "count": 0 },
+				{ "enabled": null, "active": "maybe", "status": "up", "count": 5 }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "enabled", Type: "boolean"},
+				{Selector: "active", Type: "boolean"},
+				{Selector: "status", Type: "boolean", TrueValues: []string{"up"}, FalseValues: []string{"down"}},
+				{Selector: "count", Type: "boolean"},
+			},
+		},
+		{
+			name: "epoch units",
+			responseString: `[
+				{ "s": 1325376000, "ms": "1325376000000", "us": 1325376000000000, "ns": "1325376000000000001", "fraction": 1325376000.25, "auto": 1325376000123456 },
+				{ "s": "1325376060", "ms": 1325376060000.5, "us": "1325376060000000", "ns": 1325376060000000000, "fraction": "1325376060.5", "auto": "1325376060000000" }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "s", Type: "timestamp_epoch_auto"},
+				{Selector: "ms", Type: "timestamp_epoch_auto"},
+				{Selector: "us", Type: "timestamp_epoch_us"},
+				{Selector: "ns", Type: "timestamp_epoch_ns"},
+				{Selector: "fraction", Type: "timestamp_epoch_s"},
+				{Selector: "auto", Type: "timestamp_epoch_auto"},
+			},
+		},
+		{
+			name: "numeric timestamps",
+			responseString: `[
+				{ "auto": 20220301, "compact": 20220301153000, "excel": 44621.5, "julian": 2459640.5, "layout": 202203 },
+				{ "auto": "20220302", "compact": "20220302000000", "excel": "44622", "julian": "2459641", "layout": 202204.5 },
+				{ "auto": 1646092800, "compact": null, "excel": null, "julian": null, "layout": null }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "auto", Type: "timestamp"},
+				{Selector: "compact", Type: "timestamp", TimeFormat: "yyyymmddHHMMSS"},
+				{Selector: "excel", Type: "timestamp", TimeFormat: "excel"},
+				{Selector: "julian", Type: "timestamp", TimeFormat: "julian"},
+				{Selector: "layout", Type: "timestamp", TimeFormat: "200601"},
+			},
+		},
+		{
+			name: "duration types",
+			responseString: `[
+				{ "go": "1h30m", "iso": "PT15M", "clock": "00:01:23.5", "ms": 1500, "s": "90" },
+				{ "go": "250ms", "iso": "P1DT2H", "clock": "01:23", "ms": "PT1S", "s": 1.5 },
+				{ "go": "foo", "iso": null, "clock": "1.02:03:04", "ms": null, "s": "00:10:00" }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "go", Type: "duration"},
+				{Selector: "iso", Type: "duration_s"},
+				{Selector: "clock", Type: "duration_s"},
+				{Selector: "ms", Type: "duration_ms"},
+				{Selector: "s", Type: "duration_s"},
+			},
+		},
+		{
+			name: "json fields",
+			responseString: `[
+				{ "username": "foo", "hobbies": ["reading","swimming"], "address": { "city": "london" } },
+				{ "username": "bar", "address": { "city": "paris" } }
+			]`,
+			jsonFields: true,
+		},
+		{
+			name: "json type",
+			responseString: `[
+				{ "username": "foo", "hobbies": ["reading","swimming"], "address": { "city": "london" } },
+				{ "username": "bar", "address": { "city": "paris" } }
+			]`,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "username"},
+				{Selector: "hobbies", Type: "json"},
+				{Selector: "address", Type: "json"},
+			},
+		},
+		{
+			name: "columnar auto",
+			responseString: `{
+				"meta" : {},
+				"data" : { "time": ["2011-01-01T00:00:00.000Z", "2012-01-01T00:00:00.000Z"], "host": ["foo", "bar"], "value": [1, 2.5] }
+			}`,
+			rootSelector: "data",
+			columnar:     gframer.ColumnarModeAuto,
+		},
+		{
+			name: "columnar auto with columns",
+			responseString: `{
+				"data" : { "time": ["2011-01-01T00:00:00.000Z", "2012-01-01T00:00:00.000Z"], "host": ["foo", "bar"], "value": [1, 2.5] }
+			}`,
+			rootSelector: "data",
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "time", Type: "timestamp"},
+				{Selector: "value", Alias: "val", Type: "number"},
+			},
+			columnar: gframer.ColumnarModeAuto,
+		},
+		{
+			name:           "columnar auto with unequal lengths",
+			responseString: `{ "host": ["foo", "bar"], "value": [1] }`,
+			columnar:       gframer.ColumnarModeAuto,
+		},
+		{
+			name:           "columnar force",
+			responseString: `{ "host": "foo", "time": ["2011-01-01T00:00:00.000Z", "2012-01-01T00:00:00.000Z"], "value": [1] }`,
+			columnar:       gframer.ColumnarModeForce,
+		},
+		{
+			name: "array rows with first row header",
+			responseString: `{
+				"range": "Sheet1!A1:C3",
+				"values": [["time", "host", "value"], ["2011-01-01T00:00:00.000Z", "foo", 1], ["2012-01-01T00:00:00.000Z", "bar", 2]]
+			}`,
+			rootSelector:   "values",
+			firstRowHeader: true,
+		},
+		{
+			name: "array rows with first row header and columns",
+			responseString: `{
+				"values": [["time", "host", "value"], ["2011-01-01T00:00:00.000Z", "foo", "1"], ["2012-01-01T00:00:00.000Z", "bar", "2.5"]]
+			}`,
+			rootSelector:   "values",
+			firstRowHeader: true,
+			fieldOrder:     gframer.FieldOrderColumns,
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "time", Type: "timestamp"},
+				{Selector: "value", Alias: "val", Type: "number"},
+			},
+		},
+		{
+			name:           "array rows with header and columns",
+			responseString: `[["2011-01-01T00:00:00.000Z", "foo", 1], ["2012-01-01T00:00:00.000Z", "bar", 2]]`,
+			header:         []string{"time", "host", "value"},
+			columns: []jsonFramer.ColumnSelector{
+				{Selector: "time", Type: "timestamp"},
+				{Selector: "host", Alias: "server"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gotFrame, err := jsonFramer.JsonStringToFrame(tt.responseString, jsonFramer.JSONFramerOptions{
-				FrameName:    tt.refId,
-				RootSelector: tt.rootSelector,
-				Columns:      tt.columns,
+				FrameName:      tt.refId,
+				RootSelector:   tt.rootSelector,
+				Columns:        tt.columns,
+				FieldOrder:     tt.fieldOrder,
+				Flatten:        tt.flatten,
+				InferIntegers:  tt.inferIntegers,
+				JSONFields:     tt.jsonFields,
+				Columnar:       tt.columnar,
+				FirstRowHeader: tt.firstRowHeader,
+				Header:         tt.header,
 			})
 			if tt.wantErr != nil {
 				require.NotNil(t, err)
-				require.Equal(t, tt.wantErr, err)
+				require.ErrorIs(t, err, tt.wantErr)
 				return
 			}
 			require.Nil(t, err)
@@ -247,6 +483,58 @@ func TestJsonStringToFrame(t *testing.T) {
 	}
 }
 
+func TestJsonStringToFrameErrors(t *testing.T) {
+	t.Run("invalid json should throw error", func(t *testing.T) {
+		_, err := jsonFramer.JsonStringToFrame("{\n\"foo\": 1,\n\"bar\": }", jsonFramer.JSONFramerOptions{})
+		var invalidInput *gframer.ErrInvalidInput
+		require.ErrorAs(t, err, &invalidInput)
+		require.Equal(t, "json", invalidInput.Format)
+		require.Equal(t, int64(20), invalidInput.Offset)
+		require.Equal(t, 3, invalidInput.Line)
+	})
+	t.Run("missing root should throw error", func(t *testing.T) {
+		_, err := jsonFramer.JsonStringToFrame(`{ "foo": 1 }`, jsonFramer.JSONFramerOptions{RootSelector: "$sum("})
+		var rootNotFound *gframer.ErrRootNotFound
+		require.ErrorAs(t, err, &rootNotFound)
+		require.Equal(t, "$sum(", rootNotFound.Selector)
+	})
+}
+
+func TestJsonStringToFrameLargeIntegers(t *testing.T) {
+	t.Run("selected object", func(t *testing.T) {
+		frame, err := jsonFramer.JsonStringToFrame(`[{ "user": { "id": 9007199254740993, "name": "foo" } }, { "user": { "id": 1, "name": "bar" } }]`, jsonFramer.JSONFramerOptions{
+			Columns:       []jsonFramer.ColumnSelector{{Selector: "user"}},
+			Flatten:       gframer.FlattenOptions{Enabled: true},
+			InferIntegers: true,
+		})
+		require.Nil(t, err)
+		field, _ := frame.FieldByName("user.id")
+		require.NotNil(t, field)
+		require.Equal(t, int64(9007199254740993), *field.At(0).(*int64))
+	})
+	t.Run("columnar array", func(t *testing.T) {
+		frame, err := jsonFramer.JsonStringToFrame(`{ "a": [9007199254740993, 1], "b": ["x", "y"] }`, jsonFramer.JSONFramerOptions{
+			Columns:       []jsonFramer.ColumnSelector{{Selector: "a"}, {Selector: "b"}},
+			Columnar:      gframer.ColumnarModeAuto,
+			InferIntegers: true,
+		})
+		require.Nil(t, err)
+		field, _ := frame.FieldByName("a")
+		require.NotNil(t, field)
+		require.Equal(t, int64(9007199254740993), *field.At(0).(*int64))
+	})
+	t.Run("json field", func(t *testing.T) {
+		frame, err := jsonFramer.JsonStringToFrame(`[{ "user": { "id": 9007199254740993 } }, { "user": { "id": 1 } }]`, jsonFramer.JSONFramerOptions{
+			Columns:    []jsonFramer.ColumnSelector{{Selector: "user"}},
+			JSONFields: true,
+		})
+		require.Nil(t, err)
+		field, _ := frame.FieldByName("user")
+		require.NotNil(t, field)
+		require.Equal(t, `{"id":9007199254740993}`, string(*field.At(0).(*json.RawMessage)))
+	})
+}
+
 func TestAzureFrame(t *testing.T) {
 	fileContent, err := ioutil.ReadFile("./testdata/azure/cost-management-daily.json")
 	require.Nil(t, err)